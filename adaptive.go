@@ -0,0 +1,244 @@
+package dsp
+
+// adaptiveAlgorithm selects how an AdaptiveFilter updates its weights.
+type adaptiveAlgorithm int
+
+const (
+	lms adaptiveAlgorithm = iota
+	nlms
+	rls
+)
+
+// nlmsRegularization keeps the NLMS step finite for inputs that are all zero.
+const nlmsRegularization = 1e-9
+
+// AdaptiveFilter is an FIR filter that adapts its weights so that its output
+// for the input signal x follows a desired signal d, minimizing the error
+// e = d - y. A typical use is interference cancellation: d is a sensor signal
+// that contains an interference, x a reference of the interference source, and
+// the error is the sensor signal with the interference removed.
+// Create an AdaptiveFilter with NewLMS, NewNLMS or NewRLS. The filter keeps its
+// weights and input history between calls to Process, so a long signal can be
+// processed in blocks and gives the same result as processing it at once.
+type AdaptiveFilter struct {
+	algorithm adaptiveAlgorithm
+	// stepSize is the step size for LMS and NLMS and the forgetting factor for
+	// RLS.
+	stepSize float64
+	delta    float64
+	weights  []float64
+	// history holds the last inputs, history[0] is the most recent one.
+	history []float64
+	// p is the inverse of the weighted input correlation matrix for RLS, pu
+	// holds p times the input history.
+	p  [][]float64
+	pu []float64
+}
+
+// NewLMS creates a least mean squares (LMS) adaptive filter with order weights.
+// After every sample, the weights move by stepSize*e*x in the direction that
+// reduces the squared error. Larger step sizes adapt faster but less
+// precisely, too large step sizes make the filter unstable. The upper limit
+// depends on the power of x, for a stable filter stepSize must be less than
+// 2/(order*power of x). Use NLMS to make the step size independent of it.
+// If order < 1 or stepSize <= 0, nil is returned.
+func NewLMS(order int, stepSize FLOAT) *AdaptiveFilter {
+	if order < 1 || stepSize <= 0 {
+		return nil
+	}
+	return newAdaptiveFilter(lms, order, float64(stepSize), 0)
+}
+
+// NewNLMS creates a normalized least mean squares (NLMS) adaptive filter with
+// order weights. It is like LMS, but the step is divided by the power of the
+// last order input samples, so the filter adapts at the same rate regardless
+// of the level of x. The filter is stable for 0 < stepSize < 2, 1 adapts the
+// fastest.
+// If order < 1 or stepSize is not between 0 and 2, nil is returned.
+func NewNLMS(order int, stepSize FLOAT) *AdaptiveFilter {
+	if order < 1 || !(stepSize > 0 && stepSize < 2) {
+		return nil
+	}
+	return newAdaptiveFilter(nlms, order, float64(stepSize), 0)
+}
+
+// NewRLS creates a recursive least squares (RLS) adaptive filter with order
+// weights. It minimizes the exponentially weighted sum of all past squared
+// errors, where an error from n samples ago has the weight forgetting^n. RLS
+// converges much faster than LMS and NLMS, at a cost of O(order²) per sample.
+// The forgetting factor is typically between 0.95 and 1, smaller values track
+// changes faster, 1 never forgets. delta regularizes the start of the
+// adaptation, the inverse correlation matrix starts as the identity matrix
+// divided by delta. Small values, e.g. 0.01 or less, make the filter converge
+// fast. delta biases the weights towards 0, but its influence fades with
+// forgetting^n.
+// If order < 1, forgetting is not in the range (0..1] or delta <= 0, nil is
+// returned.
+func NewRLS(order int, forgetting, delta FLOAT) *AdaptiveFilter {
+	if order < 1 || !(forgetting > 0 && forgetting <= 1) || delta <= 0 {
+		return nil
+	}
+	return newAdaptiveFilter(rls, order, float64(forgetting), float64(delta))
+}
+
+func newAdaptiveFilter(algorithm adaptiveAlgorithm, order int, stepSize, delta float64) *AdaptiveFilter {
+	f := &AdaptiveFilter{
+		algorithm: algorithm,
+		stepSize:  stepSize,
+		delta:     delta,
+		weights:   make([]float64, order),
+		history:   make([]float64, order),
+	}
+	if algorithm == rls {
+		f.p = make([][]float64, order)
+		for i := range f.p {
+			f.p[i] = make([]float64, order)
+		}
+		f.pu = make([]float64, order)
+	}
+	f.Reset()
+	return f
+}
+
+// Weights returns a copy of the filter's current weights. They are FIR taps,
+// weights[k] is applied to the input from k samples ago, so they can be used
+// with FIRFilter.
+func (f *AdaptiveFilter) Weights() []FLOAT {
+	w := make([]FLOAT, len(f.weights))
+	for i := range w {
+		w[i] = FLOAT(f.weights[i])
+	}
+	return w
+}
+
+// Reset sets the weights and the input history back to zero, as if no samples
+// had been processed.
+func (f *AdaptiveFilter) Reset() {
+	for i := range f.weights {
+		f.weights[i] = 0
+		f.history[i] = 0
+	}
+	for i := range f.p {
+		for j := range f.p[i] {
+			f.p[i][j] = 0
+		}
+		f.p[i][i] = 1 / f.delta
+	}
+}
+
+// ProcessSample filters the input sample x, adapts the weights to the desired
+// sample d and returns the output y and the error e = d - y. Both are computed
+// with the weights from before the adaptation.
+func (f *AdaptiveFilter) ProcessSample(x, d FLOAT) (y, e FLOAT) {
+	u := f.history
+	copy(u[1:], u)
+	u[0] = float64(x)
+
+	var out float64
+	for i, w := range f.weights {
+		out += w * u[i]
+	}
+	err := float64(d) - out
+
+	switch f.algorithm {
+	case lms:
+		for i := range f.weights {
+			f.weights[i] += f.stepSize * err * u[i]
+		}
+	case nlms:
+		power := nlmsRegularization
+		for _, v := range u {
+			power += v * v
+		}
+		step := f.stepSize * err / power
+		for i := range f.weights {
+			f.weights[i] += step * u[i]
+		}
+	case rls:
+		f.updateRLS(err)
+	}
+	return FLOAT(out), FLOAT(err)
+}
+
+// updateRLS updates the weights and the matrix p for the error err of the
+// current input history.
+func (f *AdaptiveFilter) updateRLS(err float64) {
+	u, p, pu, lambda := f.history, f.p, f.pu, f.stepSize
+
+	// The gain is k = p*u / (lambda + u'*p*u).
+	denominator := lambda
+	for i := range p {
+		pu[i] = 0
+		for j, v := range u {
+			pu[i] += p[i][j] * v
+		}
+		denominator += u[i] * pu[i]
+	}
+	for i := range f.weights {
+		f.weights[i] += pu[i] / denominator * err
+	}
+
+	// p = (p - k*u'*p) / lambda, where u'*p = (p*u)' because p is symmetric.
+	// Updating only one triangle and mirroring it keeps p symmetric despite
+	// rounding errors.
+	for i := range p {
+		for j := i; j < len(p); j++ {
+			v := (p[i][j] - pu[i]*pu[j]/denominator) / lambda
+			p[i][j] = v
+			p[j][i] = v
+		}
+	}
+}
+
+// Process filters the samples of x, adapting the weights to the desired signal
+// d, and returns the output y and the error e = d - y. It continues from the
+// state that the last call left behind. x and d are not modified.
+// If x and d differ in length, only the samples up to the shorter length are
+// processed and y and e have that length.
+func (f *AdaptiveFilter) Process(x, d []FLOAT) (y, e []FLOAT) {
+	n := len(x)
+	if len(d) < n {
+		n = len(d)
+	}
+	y = make([]FLOAT, n)
+	e = make([]FLOAT, n)
+	for i := range y {
+		y[i], e[i] = f.ProcessSample(x[i], d[i])
+	}
+	return y, e
+}
+
+// LMS filters x with an LMS adaptive filter, see NewLMS, adapting it to the
+// desired signal d. It returns the output, the error d - y and the final
+// weights. Use an AdaptiveFilter to process a signal in blocks.
+// If x and d differ in length, the shorter length is used, see Process. If the
+// parameters are invalid, nil is returned for all three.
+func LMS(x, d []FLOAT, order int, stepSize FLOAT) (y, e, weights []FLOAT) {
+	return adaptiveFilter(NewLMS(order, stepSize), x, d)
+}
+
+// NLMS filters x with an NLMS adaptive filter, see NewNLMS, adapting it to the
+// desired signal d. It returns the output, the error d - y and the final
+// weights. Use an AdaptiveFilter to process a signal in blocks.
+// If x and d differ in length, the shorter length is used, see Process. If the
+// parameters are invalid, nil is returned for all three.
+func NLMS(x, d []FLOAT, order int, stepSize FLOAT) (y, e, weights []FLOAT) {
+	return adaptiveFilter(NewNLMS(order, stepSize), x, d)
+}
+
+// RLS filters x with an RLS adaptive filter, see NewRLS, adapting it to the
+// desired signal d. It returns the output, the error d - y and the final
+// weights. Use an AdaptiveFilter to process a signal in blocks.
+// If x and d differ in length, the shorter length is used, see Process. If the
+// parameters are invalid, nil is returned for all three.
+func RLS(x, d []FLOAT, order int, forgetting, delta FLOAT) (y, e, weights []FLOAT) {
+	return adaptiveFilter(NewRLS(order, forgetting, delta), x, d)
+}
+
+func adaptiveFilter(f *AdaptiveFilter, x, d []FLOAT) (y, e, weights []FLOAT) {
+	if f == nil {
+		return nil, nil, nil
+	}
+	y, e = f.Process(x, d)
+	return y, e, f.Weights()
+}
@@ -0,0 +1,110 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestLMSUpdate(t *testing.T) {
+	// n = 0: u = [1 0], y = 0, e = 1, w = [0.5 0]
+	// n = 1: u = [2 1], y = 1, e = -1, w = [-0.5 -0.5]
+	y, e, w := LMS([]FLOAT{1, 2}, []FLOAT{1, 0}, 2, 0.5)
+	check.Eq(t, y, []FLOAT{0, 1})
+	check.Eq(t, e, []FLOAT{1, -1})
+	check.Eq(t, w, []FLOAT{-0.5, -0.5})
+}
+
+func TestAdaptiveFiltersIdentifySystem(t *testing.T) {
+	h := []FLOAT{0.5, -0.3, 0.2, 0.1}
+	x := noise(4000)
+	d := FIRFilter(x, h, ConvolveFull)[:len(x)]
+
+	_, e, w := LMS(x, d, 4, 0.5)
+	check.EqEps(t, w, h, 1e-3)
+	check.EqEps(t, e[3000:], Repeat(0, 1000), 1e-3)
+
+	_, e, w = NLMS(x, d, 4, 1)
+	check.EqEps(t, w, h, 1e-4)
+	check.EqEps(t, e[3000:], Repeat(0, 1000), 1e-4)
+
+	// RLS converges much faster.
+	_, e, w = RLS(x[:300], d[:300], 4, 0.99, 1e-4)
+	check.EqEps(t, w, h, 1e-4)
+	check.EqEps(t, e[50:], Repeat(0, 250), 1e-4)
+}
+
+func TestAdaptiveFilterCancelsInterference(t *testing.T) {
+	// The sensor picks up the drive signal through an unknown path.
+	const fs = 1000
+	signal := sine(10000, 0.2, 3, fs)
+	drive := noise(10000)
+	path := []FLOAT{0, 0.8, 0.4, -0.2}
+	sensor := Add(signal, FIRFilter(drive, path, ConvolveFull)[:10000])
+
+	for _, f := range []*AdaptiveFilter{
+		NewNLMS(8, 0.01),
+		NewRLS(8, 1, 0.01),
+	} {
+		_, e := f.Process(drive, sensor)
+		check.EqEps(t, e[9000:], signal[9000:], 0.05)
+		check.EqEps(t, f.Weights()[:4], path, 0.02)
+	}
+}
+
+func TestAdaptiveFilterProcessesInBlocks(t *testing.T) {
+	x := noise(300)
+	d := FIRFilter(Reverse(x), []FLOAT{1, 0.5}, ConvolveFull)[:300]
+	for _, newFilter := range []func() *AdaptiveFilter{
+		func() *AdaptiveFilter { return NewLMS(5, 0.2) },
+		func() *AdaptiveFilter { return NewNLMS(5, 0.2) },
+		func() *AdaptiveFilter { return NewRLS(5, 0.98, 0.1) },
+	} {
+		y, e := newFilter().Process(x, d)
+
+		f := newFilter()
+		y1, e1 := f.Process(x[:100], d[:100])
+		y2, e2 := f.Process(x[100:], d[100:])
+		check.Eq(t, append(y1, y2...), y)
+		check.Eq(t, append(e1, e2...), e)
+
+		f.Reset()
+		check.Eq(t, f.Weights(), Repeat(0, 5))
+		y3, _ := f.Process(x, d)
+		check.Eq(t, y3, y)
+	}
+}
+
+func TestAdaptiveFilterInvalidParameters(t *testing.T) {
+	check.Eq(t, NewLMS(0, 0.1) == nil, true)
+	check.Eq(t, NewLMS(3, 0) == nil, true)
+	check.Eq(t, NewNLMS(3, 2) == nil, true)
+	check.Eq(t, NewRLS(3, 1.1, 0.01) == nil, true)
+	check.Eq(t, NewRLS(3, 0.99, 0) == nil, true)
+
+	y, e, w := NLMS([]FLOAT{1}, []FLOAT{1}, 0, 0.5)
+	check.Eq(t, y, nil)
+	check.Eq(t, e, nil)
+	check.Eq(t, w, nil)
+
+	y, e, w = RLS(nil, nil, 2, 1, 0.01)
+	check.Eq(t, y, []FLOAT{})
+	check.Eq(t, e, []FLOAT{})
+	check.Eq(t, w, []FLOAT{0, 0})
+}
+
+func TestAdaptiveFilterUsesShorterLength(t *testing.T) {
+	x := noise(10)
+	d := noise(7)
+	y, e := NewLMS(2, 0.1).Process(x, d)
+	check.Eq(t, len(y), 7)
+	check.Eq(t, len(e), 7)
+	y2, e2 := NewLMS(2, 0.1).Process(x[:7], d)
+	check.Eq(t, y, y2)
+	check.Eq(t, e, e2)
+
+	y, e, w := RLS(x[:3], d, 2, 0.99, 0.01)
+	check.Eq(t, len(y), 3)
+	check.Eq(t, len(e), 3)
+	check.Eq(t, len(w), 2)
+}
@@ -0,0 +1,273 @@
+package dsp
+
+import "math"
+
+// DCTScaling selects the normalization of the discrete cosine transforms.
+type DCTScaling int
+
+const (
+	// DCTUnnormalized uses the plain sums with a factor of 2, e.g. for DCT-II
+	//
+	//	y[k] = 2 * sum over n of x[n] * cos(pi*k*(2n+1)/(2N))
+	//
+	// Applying a transform and its inverse scales the input by 2N.
+	DCTUnnormalized DCTScaling = iota
+
+	// DCTOrthonormal scales the transforms so that their matrices are
+	// orthonormal. The transforms then preserve energy and DCT3 is the exact
+	// inverse of DCT2, and DCT4 is its own inverse.
+	DCTOrthonormal
+)
+
+// DCT2 returns the type II discrete cosine transform of x, the transform
+// commonly referred to as "the DCT":
+//
+//	y[k] = 2 * sum over n of x[n] * cos(pi*k*(2n+1)/(2N))
+//
+// With orthonormal scaling, y[0] is multiplied by sqrt(1/(4N)) and all other
+// values by sqrt(1/(2N)). DCT3 is its inverse.
+func DCT2(x []FLOAT, scaling DCTScaling) []FLOAT {
+	n := len(x)
+	if n == 0 {
+		return nil
+	}
+
+	// Reorder x so that a single DFT of length n gives the cosine sums, see
+	// Makhoul: "A fast cosine transform in one and two dimensions".
+	v := make([]COMPLEX, n)
+	for i := 0; 2*i < n; i++ {
+		v[i] = complex(x[2*i], 0)
+	}
+	for i := 0; 2*i+1 < n; i++ {
+		v[n-1-i] = complex(x[2*i+1], 0)
+	}
+	NewFFTPlan(n).Forward(v, v)
+
+	y := make([]FLOAT, n)
+	for k := range y {
+		w := cmplxExp(-math.Pi * float64(k) / float64(2*n))
+		y[k] = FLOAT(2 * real(w*complex128(v[k])))
+	}
+	if scaling == DCTOrthonormal {
+		scaleDCT2(y, false)
+	}
+	return y
+}
+
+// DCT3 returns the type III discrete cosine transform of x:
+//
+//	y[k] = x[0] + 2 * sum over n >= 1 of x[n] * cos(pi*n*(2k+1)/(2N))
+//
+// With orthonormal scaling, x[0] is multiplied by sqrt(1/N) instead of 1 and
+// all other terms by sqrt(1/(2N)). It is the inverse of DCT2, for unnormalized
+// scaling the result is 2N times the original input.
+func DCT3(x []FLOAT, scaling DCTScaling) []FLOAT {
+	n := len(x)
+	if n == 0 {
+		return nil
+	}
+
+	X := Copy(x)
+	if scaling == DCTOrthonormal {
+		scaleDCT2(X, true)
+	}
+
+	// This undoes the steps of DCT2, see there.
+	v := make([]COMPLEX, n)
+	for k := range v {
+		var xc float64
+		if k > 0 {
+			xc = float64(X[n-k])
+		}
+		w := cmplxExp(math.Pi * float64(k) / float64(2*n))
+		v[k] = COMPLEX(w * complex(float64(X[k]), -xc) / 2)
+	}
+	NewFFTPlan(n).Inverse(v, v)
+
+	y := make([]FLOAT, n)
+	for i := 0; 2*i < n; i++ {
+		y[2*i] = real(v[i])
+	}
+	for i := 0; 2*i+1 < n; i++ {
+		y[2*i+1] = real(v[n-1-i])
+	}
+	if scaling == DCTUnnormalized {
+		for i := range y {
+			y[i] *= FLOAT(2 * n)
+		}
+	}
+	return y
+}
+
+// scaleDCT2 applies the orthonormal scaling of DCT2 to y, or undoes it if
+// inverse is true.
+func scaleDCT2(y []FLOAT, inverse bool) {
+	n := float64(len(y))
+	first := math.Sqrt(1 / (4 * n))
+	rest := math.Sqrt(1 / (2 * n))
+	if inverse {
+		first, rest = 1/first, 1/rest
+	}
+	y[0] *= FLOAT(first)
+	for i := 1; i < len(y); i++ {
+		y[i] *= FLOAT(rest)
+	}
+}
+
+// DCT4 returns the type IV discrete cosine transform of x:
+//
+//	y[k] = 2 * sum over n of x[n] * cos(pi*(2n+1)*(2k+1)/(4N))
+//
+// With orthonormal scaling, all values are multiplied by sqrt(1/(2N)). It is
+// its own inverse, for unnormalized scaling applying it twice scales the input
+// by 2N.
+func DCT4(x []FLOAT, scaling DCTScaling) []FLOAT {
+	n := len(x)
+	if n == 0 {
+		return nil
+	}
+
+	// Splitting the cosine argument into pi*(4nk + 2n + 2k + 1)/(4N) turns
+	// the sum into a DFT of length 2N with pre- and post-rotations.
+	v := make([]COMPLEX, 2*n)
+	for i := range x {
+		v[i] = COMPLEX(complex(float64(x[i]), 0) * cmplxExp(-math.Pi*float64(i)/float64(2*n)))
+	}
+	NewFFTPlan(2*n).Forward(v, v)
+
+	scale := 2.0
+	if scaling == DCTOrthonormal {
+		scale = math.Sqrt(2 / float64(n))
+	}
+	y := make([]FLOAT, n)
+	for k := range y {
+		w := cmplxExp(-math.Pi * float64(2*k+1) / float64(4*n))
+		y[k] = FLOAT(scale * real(w*complex128(v[k])))
+	}
+	return y
+}
+
+// MDCT returns the modified discrete cosine transform of the 2N samples in x,
+// multiplied with window:
+//
+//	X[k] = sum over n of w[n]*x[n] * cos(pi/N * (n + 1/2 + N/2) * (k + 1/2))
+//
+// The result has N values.
+// The MDCT is a lapped transform, it is applied to blocks of 2N samples that
+// overlap by N samples. The aliasing that each block's IMDCT contains is
+// canceled when overlap-adding the IMDCT outputs of consecutive blocks (time
+// domain aliasing cancellation, TDAC). For this to work, the window must
+// satisfy the Princen-Bradley condition w[n]² + w[n+N]² = 1, like SineWindow
+// and KaiserBesselDerived do.
+// window must either be nil for a rectangular window or have the same length
+// as x. If len(x) is zero or odd or the window length does not match, nil is
+// returned.
+func MDCT(x, window []FLOAT) []FLOAT {
+	if len(x) == 0 || len(x)%2 != 0 || window != nil && len(window) != len(x) {
+		return nil
+	}
+
+	n := len(x) / 2
+	offset := 0.5 + float64(n)/2
+
+	// The sum is a DFT of length 2N with pre- and post-rotations.
+	v := make([]COMPLEX, 2*n)
+	for i := range v {
+		s := float64(x[i])
+		if window != nil {
+			s *= float64(window[i])
+		}
+		v[i] = COMPLEX(complex(s, 0) * cmplxExp(-math.Pi*float64(i)/float64(2*n)))
+	}
+	NewFFTPlan(2*n).Forward(v, v)
+
+	X := make([]FLOAT, n)
+	for k := range X {
+		w := cmplxExp(-math.Pi * offset * float64(2*k+1) / float64(2*n))
+		X[k] = FLOAT(real(w * complex128(v[k])))
+	}
+	return X
+}
+
+// IMDCT returns the inverse modified discrete cosine transform of the N values
+// in X, multiplied with window:
+//
+//	y[n] = 2*w[n]/N * sum over k of X[k] * cos(pi/N * (n + 1/2 + N/2) * (k + 1/2))
+//
+// The result has 2N values. It is not the original block of samples, but
+// overlap-adding the results of consecutive blocks, each shifted by N samples,
+// gives back the original signal if the window satisfies the Princen-Bradley
+// condition, see MDCT.
+// window must either be nil for a rectangular window or have twice the length
+// of X. If X is empty or the window length does not match, nil is returned.
+func IMDCT(X, window []FLOAT) []FLOAT {
+	if len(X) == 0 || window != nil && len(window) != 2*len(X) {
+		return nil
+	}
+
+	n := len(X)
+	offset := 0.5 + float64(n)/2
+
+	v := make([]COMPLEX, 2*n)
+	for k := range X {
+		v[k] = COMPLEX(complex(float64(X[k]), 0) * cmplxExp(math.Pi*offset*float64(k)/float64(n)))
+	}
+	NewFFTPlan(2*n).Inverse(v, v)
+
+	// The inverse FFT divides by 2N, the result should be multiplied by 2/N.
+	y := make([]FLOAT, 2*n)
+	for i := range y {
+		w := cmplxExp(math.Pi * (float64(i) + offset) / float64(2*n))
+		s := 4 * real(w*complex128(v[i]))
+		if window != nil {
+			s *= float64(window[i])
+		}
+		y[i] = FLOAT(s)
+	}
+	return y
+}
+
+// SineWindow returns the sine window of length n for use with MDCT and IMDCT:
+//
+//	w[i] = sin(pi * (i + 1/2) / n)
+//
+// For even n it satisfies the Princen-Bradley condition. If n <= 0 the returned
+// slice is empty.
+func SineWindow(n int) []FLOAT {
+	if n <= 0 {
+		return nil
+	}
+	w := make([]FLOAT, n)
+	for i := range w {
+		w[i] = FLOAT(math.Sin(math.Pi * (float64(i) + 0.5) / float64(n)))
+	}
+	return w
+}
+
+// KaiserBesselDerived returns the Kaiser-Bessel-derived window of length n for
+// use with MDCT and IMDCT. It is built from the cumulative sums of a Kaiser
+// window with beta = pi*alpha and satisfies the Princen-Bradley condition.
+// Larger alpha give better stop band attenuation at the cost of a wider main
+// lobe, AAC uses alpha = 4 for long blocks.
+// If n is <= 0 or odd, the returned slice is empty.
+func KaiserBesselDerived(n int, alpha FLOAT) []FLOAT {
+	if n <= 0 || n%2 != 0 {
+		return nil
+	}
+
+	half := n / 2
+	kaiser := Kaiser(half+1, FLOAT(math.Pi*float64(alpha)), Symmetric)
+	var total float64
+	for _, v := range kaiser {
+		total += float64(v)
+	}
+
+	w := make([]FLOAT, n)
+	var sum float64
+	for i := 0; i < half; i++ {
+		sum += float64(kaiser[i])
+		w[i] = FLOAT(math.Sqrt(sum / total))
+		w[n-1-i] = w[i]
+	}
+	return w
+}
@@ -0,0 +1,108 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+// differenceEquation filters x with the transfer function b/a, a[0] == 1,
+// straight from its definition.
+func differenceEquation(x, b, a []float64) []float64 {
+	y := make([]float64, len(x))
+	for n := range x {
+		for k := range b {
+			if n-k >= 0 {
+				y[n] += b[k] * x[n-k]
+			}
+		}
+		for k := 1; k < len(a); k++ {
+			if n-k >= 0 {
+				y[n] -= a[k] * y[n-k]
+			}
+		}
+	}
+	return y
+}
+
+func toFloat64(x []float32) []float64 {
+	y := make([]float64, len(x))
+	for i := range x {
+		y[i] = float64(x[i])
+	}
+	return y
+}
+
+func TestBiquadMatchesDifferenceEquation(t *testing.T) {
+	s := Biquad{B0: 0.2, B1: 0.4, B2: 0.2, A1: -0.5, A2: 0.25}
+	x := realTestSignal(50)
+	want := differenceEquation(toFloat64(x), []float64{0.2, 0.4, 0.2}, []float64{1, -0.5, 0.25})
+	check.EqEps(t, toFloat64(IIRFilter(x, []Biquad{s})), want, 1e-5)
+}
+
+func TestBiquadCascadeIsProductOfSections(t *testing.T) {
+	s1 := Biquad{B0: 1, B1: 2, B2: 1, A1: -0.5, A2: 0.25}
+	s2 := Biquad{B0: 0.5, B1: -0.5, A1: 0.3}
+	x := realTestSignal(50)
+	// (1 + 2z + z²)(0.5 - 0.5z) and (1 - 0.5z + 0.25z²)(1 + 0.3z)
+	b := []float64{0.5, 0.5, -0.5, -0.5}
+	a := []float64{1, -0.2, 0.1, 0.075}
+	want := differenceEquation(toFloat64(x), b, a)
+	check.EqEps(t, toFloat64(IIRFilter(x, []Biquad{s1, s2})), want, 1e-4)
+}
+
+func TestBiquadCascadeKeepsStateAcrossCalls(t *testing.T) {
+	sections := []Biquad{
+		{B0: 0.2, B1: 0.4, B2: 0.2, A1: -0.5, A2: 0.25},
+		{B0: 1, B1: -1, A1: -0.9},
+	}
+	x := realTestSignal(100)
+	whole := IIRFilter(x, sections)
+
+	f := NewBiquadCascade(sections)
+	var chunked []float32
+	chunked = append(chunked, f.Process(x[:30])...)
+	chunked = append(chunked, f.Process(x[30:31])...)
+	chunked = append(chunked, f.Process(x[31:])...)
+	check.Eq(t, chunked, whole)
+
+	f.Reset()
+	check.Eq(t, f.Process(x), whole)
+}
+
+func TestBiquadCascadeCopiesSections(t *testing.T) {
+	sections := []Biquad{{B0: 1, A1: 0.5}}
+	f := NewBiquadCascade(sections)
+	sections[0].B0 = 2
+	check.Eq(t, f.Sections(), []Biquad{{B0: 1, A1: 0.5}})
+	f.Sections()[0].B0 = 3
+	check.Eq(t, f.Sections(), []Biquad{{B0: 1, A1: 0.5}})
+}
+
+func TestIIRFilterImpulseResponse(t *testing.T) {
+	// A one-pole lowpass y[n] = x[n] + 0.5*y[n-1].
+	y := IIRFilter([]float32{1, 0, 0, 0}, []Biquad{{B0: 1, A1: -0.5}})
+	check.Eq(t, y, []float32{1, 0.5, 0.25, 0.125})
+}
+
+func TestIIRFilterEdgeCases(t *testing.T) {
+	x := []float32{1, 2, 3}
+	check.Eq(t, IIRFilter(x, nil), x)
+	check.Eq(t, IIRFilter(nil, []Biquad{{B0: 1}}), []float32{})
+}
+
+func TestBiquadCascadeSteadyState(t *testing.T) {
+	f := NewBiquadCascade([]Biquad{
+		{B0: 0.2, B1: 0.4, B2: 0.2, A1: -0.5, A2: 0.25},
+		{B0: 1, B1: -0.5, A1: -0.9},
+	})
+	// The DC gain is 0.8/0.75 * 0.5/0.1.
+	gain := float32(0.8 / 0.75 * 5)
+	f.SetSteadyState(2)
+	check.EqEps(t, f.Process(Repeat(2, 5)), Repeat(2*gain, 5), 1e-5)
+
+	// A pole at DC never settles, the state is zero.
+	g := NewBiquadCascade([]Biquad{{B0: 1, A1: -1}})
+	g.SetSteadyState(2)
+	check.Eq(t, g.Process([]float32{1, 1}), []float32{1, 2})
+}
@@ -0,0 +1,92 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// CZT returns the chirp-Z transform of x, which evaluates the z-transform of x
+// at the m points z[k] = a * w^-k on a spiral in the z-plane:
+//
+//	X[k] = sum over n of x[n] * a^-n * w^(n*k)
+//
+// With a = 1 and w = exp(-2*pi*i/len(x)) this is the DFT. The transform is
+// computed with FFTs of power of two length, so it is fast for any combination
+// of len(x) and m.
+// If m <= 0, nil is returned.
+func CZT(x []complex64, m int, w, a complex64) []complex64 {
+	if m <= 0 {
+		return nil
+	}
+	if len(x) == 0 {
+		return make([]complex64, m)
+	}
+
+	n := len(x)
+	logW := cmplx.Log(complex128(w))
+	logA := cmplx.Log(complex128(a))
+	// chirp returns w^(t*t/2). Using n*k = (n*n + k*k - (k-n)*(k-n))/2 turns
+	// the sum into a convolution with this chirp.
+	chirp := func(t int) complex128 {
+		return cmplx.Exp(complex(float64(t)*float64(t)/2, 0) * logW)
+	}
+
+	size := nextPowerOfTwo(n + m - 1)
+	plan := NewFFTPlan(size)
+
+	y := make([]complex64, size)
+	for i := range x {
+		y[i] = complex64(complex128(x[i]) * cmplx.Exp(-complex(float64(i), 0)*logA) * chirp(i))
+	}
+	plan.Forward(y, y)
+
+	v := make([]complex64, size)
+	for k := 0; k < m; k++ {
+		v[k] = complex64(1 / chirp(k))
+	}
+	for i := 1; i < n; i++ {
+		v[size-i] = complex64(1 / chirp(i))
+	}
+	plan.Forward(v, v)
+
+	for i := range y {
+		y[i] *= v[i]
+	}
+	plan.Inverse(y, y)
+
+	X := make([]complex64, m)
+	for k := range X {
+		X[k] = complex64(complex128(y[k]) * chirp(k))
+	}
+	return X
+}
+
+// ZoomFFT evaluates the discrete time Fourier transform of x, sampled at
+// sampleRate Hz, at m frequencies evenly spaced from f1 to f2 Hz, both
+// inclusive. This gives a fine frequency resolution in a narrow band without
+// zero padding x to a huge length. The values are scaled like those of FFT,
+// i.e. the same as FFT returns for the bins that lie exactly on one of the
+// frequencies.
+// freqs holds the m frequencies in Hz. If m == 1, only f1 is evaluated. If
+// m <= 0, empty slices are returned.
+func ZoomFFT(x []complex64, f1, f2, sampleRate float32, m int) (freqs []float32, spectrum []complex64) {
+	if m <= 0 {
+		return nil, nil
+	}
+	var step float64
+	if m > 1 {
+		step = float64(f2-f1) / float64(m-1)
+	}
+	freqs = make([]float32, m)
+	for k := range freqs {
+		freqs[k] = float32(float64(f1) + float64(k)*step)
+	}
+	a := complex64(cmplxExp(2 * math.Pi * float64(f1) / float64(sampleRate)))
+	w := complex64(cmplxExp(-2 * math.Pi * step / float64(sampleRate)))
+	return freqs, CZT(x, m, w, a)
+}
+
+// ZoomRFFT is the same as ZoomFFT for a real signal x.
+func ZoomRFFT(x []float32, f1, f2, sampleRate float32, m int) (freqs []float32, spectrum []complex64) {
+	return ZoomFFT(ToComplex(x), f1, f2, sampleRate, m)
+}
@@ -0,0 +1,118 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func naiveCosineSum(x []float32, m int, arg func(n, k int) float64) []float32 {
+	y := make([]float32, m)
+	for k := range y {
+		var sum float64
+		for n := range x {
+			sum += float64(x[n]) * math.Cos(arg(n, k))
+		}
+		y[k] = float32(sum)
+	}
+	return y
+}
+
+func TestDCT2(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 8, 13} {
+		x := realTestSignal(n)
+		want := naiveCosineSum(x, n, func(i, k int) float64 {
+			return math.Pi * float64(k*(2*i+1)) / float64(2*n)
+		})
+		check.EqEps(t, DCT2(x, DCTUnnormalized), Scale(want, 2), 1e-4, "n=", n)
+	}
+	check.Eq(t, DCT2(nil, DCTUnnormalized), nil)
+}
+
+func TestDCT3(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 8, 13} {
+		x := realTestSignal(n)
+		want := naiveCosineSum(x[1:], n, func(i, k int) float64 {
+			return math.Pi * float64((i+1)*(2*k+1)) / float64(2*n)
+		})
+		want = AddOffset(Scale(want, 2), x[0])
+		check.EqEps(t, DCT3(x, DCTUnnormalized), want, 1e-4, "n=", n)
+	}
+	check.Eq(t, DCT3(nil, DCTOrthonormal), nil)
+}
+
+func TestDCT4(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 8, 13} {
+		x := realTestSignal(n)
+		want := naiveCosineSum(x, n, func(i, k int) float64 {
+			return math.Pi * float64((2*i+1)*(2*k+1)) / float64(4*n)
+		})
+		check.EqEps(t, DCT4(x, DCTUnnormalized), Scale(want, 2), 1e-4, "n=", n)
+	}
+	check.Eq(t, DCT4(nil, DCTUnnormalized), nil)
+}
+
+func TestDCTInverses(t *testing.T) {
+	for _, n := range []int{1, 4, 7, 32} {
+		x := realTestSignal(n)
+		check.EqEps(t, DCT3(DCT2(x, DCTOrthonormal), DCTOrthonormal), x, 1e-5)
+		check.EqEps(t, DCT4(DCT4(x, DCTOrthonormal), DCTOrthonormal), x, 1e-5)
+		scale := 1 / float32(2*n)
+		check.EqEps(t, Scale(DCT3(DCT2(x, DCTUnnormalized), DCTUnnormalized), scale), x, 1e-5)
+		check.EqEps(t, Scale(DCT4(DCT4(x, DCTUnnormalized), DCTUnnormalized), scale), x, 1e-5)
+	}
+}
+
+func TestOrthonormalDCTPreservesEnergy(t *testing.T) {
+	x := realTestSignal(20)
+	check.EqEps(t, sumOfSquares(DCT2(x, DCTOrthonormal)), sumOfSquares(x), 1e-4)
+	check.EqEps(t, sumOfSquares(DCT4(x, DCTOrthonormal)), sumOfSquares(x), 1e-4)
+}
+
+func TestMDCT(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 8} {
+		x := realTestSignal(2 * n)
+		offset := 0.5 + float64(n)/2
+		arg := func(i, k int) float64 {
+			return math.Pi / float64(n) * (float64(i) + offset) * (float64(k) + 0.5)
+		}
+		check.EqEps(t, MDCT(x, nil), naiveCosineSum(x, n, arg), 1e-4, "n=", n)
+
+		X := x[:n]
+		want := naiveCosineSum(X, 2*n, func(k, i int) float64 { return arg(i, k) })
+		check.EqEps(t, IMDCT(X, nil), Scale(want, 2/float32(n)), 1e-4, "n=", n)
+	}
+	check.Eq(t, MDCT(nil, nil), nil)
+	check.Eq(t, MDCT([]float32{1, 2, 3}, nil), nil)
+	check.Eq(t, IMDCT(nil, nil), nil)
+	check.Eq(t, MDCT([]float32{1, 2}, []float32{1}), nil)
+	check.Eq(t, IMDCT([]float32{1, 2}, []float32{1, 1}), nil)
+}
+
+func TestMDCTWindowsSatisfyPrincenBradley(t *testing.T) {
+	for _, w := range [][]float32{SineWindow(16), KaiserBesselDerived(16, 4)} {
+		for i := 0; i < 8; i++ {
+			check.EqEps(t, w[i]*w[i]+w[i+8]*w[i+8], 1, 1e-6)
+			check.EqEps(t, w[i], w[15-i], 1e-6)
+		}
+	}
+	check.Eq(t, SineWindow(0), nil)
+	check.Eq(t, KaiserBesselDerived(7, 4), nil)
+}
+
+func TestIMDCTOverlapAddReconstructsSignal(t *testing.T) {
+	n := 16
+	x := append(make([]float32, n), realTestSignal(10*n)...)
+	x = append(x, make([]float32, n)...)
+	for _, window := range [][]float32{SineWindow(2 * n), KaiserBesselDerived(2*n, 4)} {
+		y := make([]float32, len(x))
+		for start := 0; start+2*n <= len(x); start += n {
+			block := IMDCT(MDCT(x[start:start+2*n], window), window)
+			for i := range block {
+				y[start+i] += block[i]
+			}
+		}
+		check.EqEps(t, y, x, 1e-5)
+	}
+}
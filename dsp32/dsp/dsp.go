@@ -1,9 +1,6 @@
 package dsp
 
-import (
-	"math"
-	"sort"
-)
+import "math"
 
 // Copy returns a copy of the given slice.
 func Copy(a []float32) []float32 {
@@ -94,11 +91,14 @@ func AverageFilter(a []float32, width int) []float32 {
 
 // MedianFilter returns a new array of median filtered values over a. The
 // resulting array is width-1 smaller than a. Neighboring elements (width
-// neighbors) are sorted and the middle element replaces the origial.
+// neighbors) are sorted and the middle element replaces the origial. For even
+// widths, the upper of the two middle elements is used.
 // If the width is 1 or smaller, a copy of the input array is returned.
 // If width is greater than len(a), a one-element array with the median value
 // over a is returned.
 // For an empty input an empty output is returned.
+// The window is updated incrementally, so the run time grows only with the
+// logarithm of width.
 func MedianFilter(a []float32, width int) []float32 {
 	if width >= len(a) {
 		width = len(a)
@@ -108,12 +108,12 @@ func MedianFilter(a []float32, width int) []float32 {
 		return Copy(a)
 	}
 
-	buf := make([]float32, width)
 	b := make([]float32, len(a)-width+1)
-	for i := range b {
-		copy(buf, a[i:])
-		sort.Sort(floats(buf))
-		b[i] = buf[width/2]
+	window := newSlidingRank(a[:width], width/2)
+	b[0] = window.value()
+	for i := 1; i < len(b); i++ {
+		window.replace((i-1)%width, a[i+width-1])
+		b[i] = window.value()
 	}
 	return b
 }
@@ -323,6 +323,54 @@ func AbsValue(x float32) float32 {
 	return -x
 }
 
+// Magnitude returns a new array of the absolute values of the complex values
+// in c, i.e. sqrt(re*re + im*im).
+func Magnitude(c []complex64) []float32 {
+	m := make([]float32, len(c))
+	for i := range m {
+		m[i] = float32(math.Hypot(float64(real(c[i])), float64(imag(c[i]))))
+	}
+	return m
+}
+
+// Phase returns a new array of the angles of the complex values in c, in
+// radians in the range [-Pi, Pi].
+func Phase(c []complex64) []float32 {
+	p := make([]float32, len(c))
+	for i := range p {
+		p[i] = float32(math.Atan2(float64(imag(c[i])), float64(real(c[i]))))
+	}
+	return p
+}
+
+// Unwrap returns a copy of the phase angles in p, in radians, with jumps of
+// more than Pi between neighboring values removed by adding multiples of 2*Pi.
+// This turns the phase of e.g. Phase, which is limited to [-Pi, Pi], into a
+// continuous curve.
+func Unwrap(p []float32) []float32 {
+	u := make([]float32, len(p))
+	var offset float64
+	for i := range u {
+		if i > 0 {
+			d := float64(p[i]) - float64(p[i-1])
+			offset -= 2 * math.Pi * math.Floor((d+math.Pi)/(2*math.Pi))
+		}
+		u[i] = float32(float64(p[i]) + offset)
+	}
+	return u
+}
+
+// Power returns a new array of the squared absolute values of the complex
+// values in c, i.e. re*re + im*im.
+func Power(c []complex64) []float32 {
+	p := make([]float32, len(c))
+	for i := range p {
+		re, im := real(c[i]), imag(c[i])
+		p[i] = re*re + im*im
+	}
+	return p
+}
+
 // Range returns an array containing all integer numbers in the range from a to
 // b, both inclusive. The order of the number is the same as the order from a to
 // b.
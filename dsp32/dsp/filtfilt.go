@@ -0,0 +1,133 @@
+package dsp
+
+// PadMode selects how a signal is extended at its ends before zero-phase
+// filtering, to reduce the transients at the start and end of the result.
+type PadMode int
+
+const (
+	// PadOdd extends the signal by point reflection at its end points, e.g.
+	// 1, 2, 4 is extended to ... -2, 0, 1, 2, 4, 6, 7 ... This continues the
+	// slope of the signal and is usually the best choice.
+	PadOdd PadMode = iota
+
+	// PadEven extends the signal by mirroring it at its end points, e.g.
+	// 1, 2, 4 is extended to ... 4, 2, 1, 2, 4, 2, 1 ...
+	PadEven
+
+	// PadConstant extends the signal by repeating its end points, e.g.
+	// 1, 2, 4 is extended to ... 1, 1, 1, 2, 4, 4, 4 ...
+	PadConstant
+
+	// PadNone does not extend the signal.
+	PadNone
+)
+
+// FIRFiltFilt filters x with the FIR filter taps twice, once forward and once
+// backward, which cancels the phase shift of the filter. Features in the result
+// are exactly aligned with those in x, and the magnitude response is the square
+// of that of the filter.
+// Before filtering, x is extended by padLen samples at both ends as selected by
+// pad, and each filter pass starts in the steady state for the first sample, so
+// the ends of the result do not ring. If padLen < 0, a default of 3*len(taps)
+// is used. padLen is limited to len(x)-1.
+// The result has the same length as x. If x is empty, an empty slice is
+// returned. If taps is empty, a copy of x is returned.
+func FIRFiltFilt(x, taps []float32, pad PadMode, padLen int) []float32 {
+	if len(taps) == 0 || len(x) == 0 {
+		return Copy(x)
+	}
+	if padLen < 0 {
+		padLen = 3 * len(taps)
+	}
+	pass := func(a []float32) []float32 {
+		// Starting in the steady state is the same as filtering a signal
+		// that was a[0] forever before.
+		m := len(taps) - 1
+		ext := append(Repeat(a[0], m), a...)
+		return Convolve(ext, taps, ConvolveFull)[m : m+len(a)]
+	}
+	return filtFilt(x, pad, padLen, pass)
+}
+
+// IIRFiltFilt filters x with the cascade of second order sections twice, once
+// forward and once backward, which cancels the phase shift of the filter. It is
+// the equivalent of FIRFiltFilt for IIR filters, see there. If padLen < 0, a
+// default of 3*(order+1) is used, where order is the order of the whole
+// cascade, the larger of the degrees of its numerator and denominator.
+func IIRFiltFilt(x []float32, sections []Biquad, pad PadMode, padLen int) []float32 {
+	if len(sections) == 0 || len(x) == 0 {
+		return Copy(x)
+	}
+	if padLen < 0 {
+		padLen = 3 * (cascadeOrder(sections) + 1)
+	}
+	f := NewBiquadCascade(sections)
+	pass := func(a []float32) []float32 {
+		f.SetSteadyState(a[0])
+		return f.Process(a)
+	}
+	return filtFilt(x, pad, padLen, pass)
+}
+
+// cascadeOrder returns the order of the transfer function of the sections.
+// Coefficients B2, A2 or B1, A1 of 0 lower the degree of a section.
+func cascadeOrder(sections []Biquad) int {
+	var num, den int
+	for _, s := range sections {
+		switch {
+		case s.B2 != 0:
+			num += 2
+		case s.B1 != 0:
+			num++
+		}
+		switch {
+		case s.A2 != 0:
+			den += 2
+		case s.A1 != 0:
+			den++
+		}
+	}
+	if num > den {
+		return num
+	}
+	return den
+}
+
+// filtFilt pads x, applies the filter forward and backward and removes the
+// padding again.
+func filtFilt(x []float32, pad PadMode, padLen int, filter func([]float32) []float32) []float32 {
+	if pad == PadNone {
+		padLen = 0
+	}
+	if padLen > len(x)-1 {
+		padLen = len(x) - 1
+	}
+	ext := padSignal(x, pad, padLen)
+	y := filter(ext)
+	y = Reverse(filter(Reverse(y)))
+	return y[padLen : padLen+len(x)]
+}
+
+// padSignal returns x extended by n samples at both ends, n < len(x).
+func padSignal(x []float32, pad PadMode, n int) []float32 {
+	last := len(x) - 1
+	ext := make([]float32, len(x)+2*n)
+	copy(ext[n:], x)
+	for i := 1; i <= n; i++ {
+		var left, right float32
+		switch pad {
+		case PadOdd:
+			left = 2*x[0] - x[i]
+			right = 2*x[last] - x[last-i]
+		case PadEven:
+			left = x[i]
+			right = x[last-i]
+		default:
+			left = x[0]
+			right = x[last]
+		}
+		ext[n-i] = left
+		ext[n+last+i] = right
+	}
+	return ext
+}
@@ -0,0 +1,70 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func naiveDTFT(x []float32, freq, sampleRate float64) complex128 {
+	var sum complex128
+	for n, v := range x {
+		theta := -2 * math.Pi * freq / sampleRate * float64(n)
+		sum += complex(float64(v)*math.Cos(theta), float64(v)*math.Sin(theta))
+	}
+	return sum
+}
+
+func TestGoertzelMatchesFFTBin(t *testing.T) {
+	x := realTestSignal(64)
+	spectrum := FFT(ToComplex(x))
+	for _, bin := range []int{0, 1, 5, 32, 63} {
+		check.EqEps(t, Goertzel(x, float32(bin), 64), spectrum[bin], 1e-4, "bin ", bin)
+	}
+}
+
+func TestGoertzelWorksBetweenBins(t *testing.T) {
+	x := realTestSignal(100)
+	for _, freq := range []float64{0.3, 7.25, 33.333} {
+		want := complex64(naiveDTFT(x, freq, 100))
+		check.EqEps(t, Goertzel(x, float32(freq), 100), want, 1e-4)
+		check.EqEps(t, GoertzelPower(x, float32(freq), 100), Power([]complex64{want})[0], 1e-3)
+	}
+}
+
+func TestGoertzelFindsToneAmplitude(t *testing.T) {
+	x := sine(1000, 3, 50, 1000)
+	amplitude := 2 * Magnitude([]complex64{Goertzel(x, 50, 1000)})[0] / 1000
+	check.EqEps(t, amplitude, 3, 1e-4)
+	check.Eq(t, Goertzel(nil, 50, 1000), complex64(0))
+	check.Eq(t, GoertzelPower(nil, 50, 1000), 0)
+}
+
+func TestSlidingGoertzelMatchesBlockGoertzel(t *testing.T) {
+	x := realTestSignal(500)
+	n := 37
+	g := NewSlidingGoertzel(n, 12.3, 100)
+	padded := append(make([]float32, n-1), x...)
+	values := g.Process(x)
+	for i := range x {
+		want := Goertzel(padded[i:i+n], 12.3, 100)
+		check.EqEps(t, values[i], want, 1e-3, "sample ", i)
+	}
+	check.Eq(t, g.Value(), values[len(values)-1])
+	check.EqEps(t, g.Power(), GoertzelPower(x[len(x)-n:], 12.3, 100), 1e-2)
+}
+
+func TestSlidingGoertzelCanBeReset(t *testing.T) {
+	g := NewSlidingGoertzel(4, 1, 4)
+	g.Process([]float32{1, 2, 3, 4, 5})
+	g.Reset()
+	check.Eq(t, g.Value(), complex64(0))
+	check.EqEps(t, g.Add(2), Goertzel([]float32{0, 0, 0, 2}, 1, 4), 1e-6)
+}
+
+func TestSlidingGoertzelWithInvalidLengthUsesOneSample(t *testing.T) {
+	g := NewSlidingGoertzel(0, 1, 4)
+	check.EqEps(t, g.Add(3), complex64(3), 1e-6)
+	check.EqEps(t, g.Add(5), complex64(5), 1e-6)
+}
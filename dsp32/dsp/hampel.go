@@ -0,0 +1,95 @@
+package dsp
+
+// madScale converts the median absolute deviation of normally distributed
+// data to its standard deviation.
+const madScale = 1.4826
+
+// Hampel removes isolated outliers, e.g. spikes, from a while leaving the rest
+// of the signal untouched. For every sample, the median and the median absolute
+// deviation (MAD) of the window of width samples centered on it are computed.
+// The MAD is scaled to estimate the standard deviation sigma. If the sample
+// deviates from the median by more than k*sigma, it is replaced by the median.
+// Typical values are width = 7 and k = 3, smaller values of k replace more
+// samples, k = 0 makes this a MedianFilterSame.
+// The window is placed like for MedianFilterSame with EdgeShrink, it shrinks
+// near the ends of a. Medians are computed like in MedianFilter, for even
+// window sizes the upper of the two middle values is used.
+// The cleaned signal has the same length as a. The indices of the replaced
+// samples are returned in ascending order.
+// If the width is 1 or smaller, a copy of a and no indices are returned. If k
+// is negative, nil is returned.
+func Hampel(a []float32, width int, k float32) (cleaned []float32, replaced []int) {
+	if k < 0 {
+		return nil, nil
+	}
+	cleaned = Copy(a)
+	if width <= 1 {
+		return cleaned, nil
+	}
+
+	medians := MedianFilterSame(a, width, EdgeShrink, 0)
+	deviations := make([]float32, width)
+	for i, x := range a {
+		start, end := shrunkWindow(i, len(a), width)
+		median := medians[i]
+		w := deviations[:end-start]
+		for j, v := range a[start:end] {
+			w[j] = AbsValue(v - median)
+		}
+		sigma := madScale * selectRank(w, len(w)/2)
+
+		if AbsValue(x-median) > k*sigma {
+			cleaned[i] = median
+			replaced = append(replaced, i)
+		}
+	}
+	return cleaned, replaced
+}
+
+// selectRank returns the value at index k of the sorted a, in linear time on
+// average. a is reordered in the process.
+func selectRank(a []float32, k int) float32 {
+	lo, hi := 0, len(a)-1
+	for lo < hi {
+		pivot := medianOfThree(a[lo], a[lo+(hi-lo)/2], a[hi])
+		// Partition a[lo:hi+1] into the values less than, equal to and
+		// greater than the pivot. Grouping the equal values keeps this fast
+		// for the many duplicates that deviations tend to have.
+		less, i, greater := lo, lo, hi
+		for i <= greater {
+			switch {
+			case a[i] < pivot:
+				a[less], a[i] = a[i], a[less]
+				less++
+				i++
+			case a[i] > pivot:
+				a[i], a[greater] = a[greater], a[i]
+				greater--
+			default:
+				i++
+			}
+		}
+		switch {
+		case k < less:
+			hi = less - 1
+		case k > greater:
+			lo = greater + 1
+		default:
+			return pivot
+		}
+	}
+	return a[k]
+}
+
+func medianOfThree(a, b, c float32) float32 {
+	if a > b {
+		a, b = b, a
+	}
+	if b > c {
+		b = c
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestHampelReplacesSpikes(t *testing.T) {
+	x := sine(200, 1, 5, 1000)
+	spiky := Copy(x)
+	spiky[0] = 5
+	spiky[50] = -4
+	spiky[51] = 3
+	spiky[120] = 10
+	spiky[199] = -6
+
+	cleaned, replaced := Hampel(spiky, 7, 3)
+	check.Eq(t, replaced, []int{0, 50, 51, 120, 199})
+	check.EqEps(t, cleaned, x, 0.1)
+
+	// Samples that are not outliers stay untouched.
+	for i := range x {
+		if i != 0 && i != 50 && i != 51 && i != 120 && i != 199 {
+			check.Eq(t, cleaned[i], x[i], i)
+		}
+	}
+}
+
+func TestHampelKeepsSmoothSignals(t *testing.T) {
+	x := Add(sine(500, 1, 3, 1000), Scale(noise(500), 0.001))
+	cleaned, replaced := Hampel(x, 9, 3)
+	check.Eq(t, len(replaced), 0)
+	check.Eq(t, cleaned, x)
+
+	// With k = 0, every sample that is not the median is replaced.
+	n := noise(500)
+	cleaned, _ = Hampel(n, 9, 0)
+	check.Eq(t, cleaned, MedianFilterSame(n, 9, EdgeShrink, 0))
+}
+
+func TestHampelOnConstantSignal(t *testing.T) {
+	// A MAD of 0 makes every deviation an outlier.
+	cleaned, replaced := Hampel([]float32{2, 2, 2, 2.5, 2, 2}, 5, 3)
+	check.Eq(t, cleaned, []float32{2, 2, 2, 2, 2, 2})
+	check.Eq(t, replaced, []int{3})
+}
+
+func TestHampelEdgeCases(t *testing.T) {
+	a := []float32{1, 9, 1}
+	cleaned, replaced := Hampel(a, 1, 3)
+	check.Eq(t, cleaned, a)
+	check.Eq(t, len(replaced), 0)
+
+	cleaned, replaced = Hampel(nil, 5, 3)
+	check.Eq(t, cleaned, []float32{})
+	check.Eq(t, len(replaced), 0)
+
+	cleaned, replaced = Hampel(a, 5, -1)
+	check.Eq(t, cleaned, nil)
+	check.Eq(t, replaced, nil)
+}
+
+func TestSelectRank(t *testing.T) {
+	x := noise(101)
+	for i := range x {
+		x[i] = float32(math.Floor(float64(x[i]) * 6))
+	}
+	sorted := Copy(x)
+	sort.Sort(floats(sorted))
+	for k := range x {
+		check.Eq(t, selectRank(Copy(x), k), sorted[k], k)
+	}
+	check.Eq(t, selectRank([]float32{3}, 0), float32(3))
+	check.Eq(t, selectRank([]float32{2, 2, 2, 1}, 0), float32(1))
+	check.Eq(t, medianOfThree(3, 1, 2), float32(2))
+	check.Eq(t, medianOfThree(1, 3, 2), float32(2))
+	check.Eq(t, medianOfThree(2, 3, 1), float32(2))
+}
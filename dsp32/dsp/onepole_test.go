@@ -0,0 +1,75 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestEMA(t *testing.T) {
+	check.Eq(t, EMA([]float32{2, 4, 4, 0}, 0.5), []float32{2, 3, 3.5, 1.75})
+	check.Eq(t, EMA([]float32{2, 4, 4, 0}, 1), []float32{2, 4, 4, 0})
+	check.Eq(t, EMA(nil, 0.5), []float32{})
+	check.Eq(t, EMA([]float32{1}, 0), nil)
+	check.Eq(t, EMA([]float32{1}, 1.5), nil)
+}
+
+func TestOnePoleMatchesDifferenceEquation(t *testing.T) {
+	// Starting from a zero state, the lowpass is alpha/(1 - (1-alpha)*z^-1).
+	const alpha = 0.1
+	x := realTestSignal(100)
+	f := NewOnePole(Lowpass, alpha)
+	want := differenceEquation(toFloat64s(x), []float64{alpha}, []float64{1, alpha - 1})
+	check.EqEps(t, toFloat64s(f.Process(x)), want, 1e-5)
+	check.EqEps(t, f.Alpha(), float32(alpha), 0)
+}
+
+func TestOnePoleLowpassAndHighpassAddUpToInput(t *testing.T) {
+	x := AddOffset(realTestSignal(100), 5)
+	low := OnePoleFilter(x, Lowpass, 0.05)
+	high := OnePoleFilter(x, Highpass, 0.05)
+	check.EqEps(t, Add(low, high), x, 1e-5)
+
+	// The highpass removes the DC offset.
+	check.Eq(t, high[0], float32(0))
+	dc := OnePoleFilter(Repeat(3, 50), Highpass, 0.2)
+	check.Eq(t, dc, Repeat(0, 50))
+}
+
+func TestOnePoleProcessesInChunks(t *testing.T) {
+	x := realTestSignal(90)
+	whole := NewOnePole(Highpass, 0.3).Process(x)
+	f := NewOnePole(Highpass, 0.3)
+	chunks := append(f.Process(x[:40]), f.Process(x[40:])...)
+	check.Eq(t, chunks, whole)
+
+	f.Reset()
+	check.Eq(t, f.Process(x), whole)
+}
+
+func TestOnePoleTimeConstant(t *testing.T) {
+	// The step response reaches 1-1/e after one time constant.
+	const fs = 1000
+	alpha := AlphaFromTimeConstant(0.05, fs)
+	y := NewOnePole(Lowpass, alpha).Process(Repeat(1, 100))
+	check.EqEps(t, float64(y[49]), 1-1/math.E, 1e-4)
+
+	check.Eq(t, AlphaFromTimeConstant(0, fs), float32(1))
+	check.Eq(t, AlphaFromTimeConstant(-1, fs), float32(0))
+	check.Eq(t, AlphaFromTimeConstant(1, 0), float32(0))
+}
+
+func TestOnePoleCutoff(t *testing.T) {
+	const fs, cutoff = 48000, 100
+	alpha := AlphaFromCutoff(cutoff, fs)
+	check.EqEps(t, alpha, AlphaFromTimeConstant(float32(1/(2*math.Pi*cutoff)), fs), 1e-7)
+	h := FreqResponse([]float32{alpha}, []float32{1, alpha - 1}, []float32{0, cutoff}, fs)
+	gain := Magnitude(h)
+	check.EqEps(t, float64(gain[0]), 1, 1e-5)
+	check.EqEps(t, float64(gain[1]), math.Sqrt(0.5), 1e-3)
+
+	check.Eq(t, AlphaFromCutoff(0, fs), float32(0))
+	check.Eq(t, NewOnePole(Bandpass, 0.5) == nil, true)
+	check.Eq(t, NewOnePole(Lowpass, AlphaFromCutoff(0, fs)) == nil, true)
+}
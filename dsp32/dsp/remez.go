@@ -0,0 +1,446 @@
+package dsp
+
+import "math"
+
+// RemezType selects the kind of filter that Remez designs.
+type RemezType int
+
+const (
+	// RemezBandpass designs a multi-band filter with a symmetric impulse
+	// response, which includes lowpass, highpass, bandpass and bandstop
+	// filters. The desired response is constant in each band.
+	RemezBandpass RemezType = iota
+
+	// RemezDifferentiator designs a differentiator with an antisymmetric
+	// impulse response. The desired response in band i is desired[i] times the
+	// frequency in cycles per sample, i.e. desired = 2*pi approximates the
+	// derivative per sample. Even tap counts give better differentiators,
+	// odd ones have a forced zero at the Nyquist frequency.
+	RemezDifferentiator
+
+	// RemezHilbert designs a Hilbert transformer with an antisymmetric impulse
+	// response, i.e. a filter with a constant gain and a phase shift of -90°,
+	// which turns cosines into sines like Hilbert does. The response at DC is
+	// always zero, so the bands should not start at 0.
+	RemezHilbert
+)
+
+// Remez designs a linear phase FIR filter with numTaps taps using the
+// Parks-McClellan algorithm. The resulting filter has the minimal maximum
+// weighted deviation from the desired response in the given bands, its error
+// ripples with equal height (equiripple), which uses the taps much more
+// efficiently than windowed designs.
+// bands holds the pairs of band edges in Hz, e.g. {0, 1000, 1500, 5000} for a
+// pass band and a stop band for signals sampled at sampleRate = 10000 Hz. The
+// frequencies between bands are transition bands where the response is left
+// free. desired holds the desired gain of each band and weights the relative
+// weight of the errors in each band, e.g. a weight of 10 in the stop band makes
+// its ripple 10 times smaller than that in the pass band. weights may be nil to
+// weigh all bands equally.
+// If the parameters are inconsistent, i.e. numTaps < 1, len(bands) is not
+// 2*len(desired), len(weights) is not len(desired), or the band edges are not
+// increasing in the range from 0 to sampleRate/2, nil is returned.
+// A RemezBandpass filter with an even number of taps always has a zero at the
+// Nyquist frequency, so for a band that ends at sampleRate/2 with a desired
+// gain other than 0, nil is returned. nil is also returned if the algorithm
+// does not converge.
+func Remez(numTaps int, bands, desired, weights []float32, sampleRate float32, kind RemezType) []float32 {
+	nbands := len(desired)
+	if numTaps < 1 || nbands == 0 || len(bands) != 2*nbands {
+		return nil
+	}
+	if weights == nil {
+		weights = Repeat(1, nbands)
+	}
+	if len(weights) != nbands {
+		return nil
+	}
+	edges := make([]float64, len(bands))
+	for i := range bands {
+		edges[i] = float64(bands[i]) / float64(sampleRate)
+		if edges[i] < 0 || edges[i] > 0.5 || i > 0 && edges[i] < edges[i-1] {
+			return nil
+		}
+	}
+
+	neg := kind != RemezBandpass
+	odd := numTaps%2 == 1
+	if !neg && !odd {
+		for b := 0; b < nbands; b++ {
+			if edges[2*b+1] >= 0.5 && desired[b] != 0 {
+				return nil
+			}
+		}
+	}
+	nfcns := numTaps / 2
+	if odd && !neg {
+		nfcns++
+	}
+	if nfcns < 1 {
+		return nil
+	}
+
+	// Build the dense frequency grid over all bands with the desired response
+	// and weight at each point.
+	delf := 0.5 / float64(16*nfcns)
+	var grid, des, wt []float64
+	for b := 0; b < nbands; b++ {
+		lo, hi := edges[2*b], edges[2*b+1]
+		if neg && b == 0 && lo < delf {
+			lo = delf
+		}
+		add := func(f float64) {
+			d, w := float64(desired[b]), float64(weights[b])
+			if kind == RemezDifferentiator {
+				// Weighing the error relative to the desired slope keeps
+				// the relative error constant over the band.
+				if d >= 0.0001 {
+					w /= f
+				}
+				d *= f
+			}
+			grid = append(grid, f)
+			des = append(des, d)
+			wt = append(wt, w)
+		}
+		for f := lo; f < hi; f += delf {
+			add(f)
+		}
+		add(hi)
+	}
+	// Filters of these types have a forced zero at the Nyquist frequency.
+	if neg == odd && grid[len(grid)-1] > 0.5-delf {
+		grid = grid[:len(grid)-1]
+		des = des[:len(des)-1]
+		wt = wt[:len(wt)-1]
+	}
+	if len(grid) < nfcns+1 {
+		return nil
+	}
+
+	// All four filter types can be written as a fixed factor Q(f) times a
+	// cosine sum. Dividing the desired response by Q and multiplying the
+	// weight with it turns the problem into approximating with a pure cosine
+	// sum.
+	for i, f := range grid {
+		var q float64
+		switch {
+		case !neg && odd:
+			continue
+		case !neg && !odd:
+			q = math.Cos(math.Pi * f)
+		case neg && !odd:
+			q = math.Sin(math.Pi * f)
+		default:
+			q = math.Sin(2 * math.Pi * f)
+		}
+		des[i] /= q
+		wt[i] *= q
+	}
+
+	alpha := remezExchange(grid, des, wt, nfcns)
+	if alpha == nil {
+		return nil
+	}
+	taps := remezImpulseResponse(alpha, numTaps, nfcns, neg, odd)
+	if kind == RemezHilbert {
+		// The antisymmetric taps have the response i*A(f), which suits the
+		// differentiator. Negating them gives the -i of the Hilbert transform.
+		for i := range taps {
+			taps[i] = -taps[i]
+		}
+	}
+	return taps
+}
+
+// remezExchange finds the cosine sum with nfcns coefficients that best
+// approximates des on the grid, in the weighted minimax sense. It returns the
+// coefficients alpha of A(f) = sum over k of alpha[k]*cos(2*pi*k*f), or nil if
+// the exchange does not converge.
+func remezExchange(grid, des, wt []float64, nfcns int) []float64 {
+	r := nfcns + 1
+	ngrid := len(grid)
+
+	// Start with extremal frequencies evenly spread over the grid.
+	ext := make([]int, r)
+	for i := 0; i < nfcns; i++ {
+		ext[i] = i * (ngrid - 1) / nfcns
+	}
+	ext[nfcns] = ngrid - 1
+
+	x := make([]float64, r)
+	ad := make([]float64, r)
+	y := make([]float64, r)
+	errs := make([]float64, ngrid)
+
+	// interpolate evaluates the polynomial in x = cos(2*pi*f) that goes
+	// through the points (x, y) with the barycentric formula.
+	interpolate := func(xf float64) float64 {
+		var num, den float64
+		for i := range x {
+			d := xf - x[i]
+			if d == 0 {
+				return y[i]
+			}
+			c := ad[i] / d
+			num += c * y[i]
+			den += c
+		}
+		return num / den
+	}
+
+	// The deviation |delta| of every alternating set is a lower bound of the
+	// optimal error, the largest error of every approximation an upper bound.
+	// The exchange has converged when both bounds meet, or when the error is
+	// at the level of rounding errors, where the exchange only chases noise.
+	// scale is the size of the weighted desired response.
+	var scale float64
+	for g := range grid {
+		scale = math.Max(scale, math.Abs(des[g]*wt[g]))
+	}
+	lower, best := 0.0, math.Inf(1)
+	bestX := make([]float64, r)
+	bestAD := make([]float64, r)
+	bestY := make([]float64, r)
+
+	converged := false
+	for iter := 0; iter < 250 && !converged; iter++ {
+		for i := range x {
+			x[i] = math.Cos(2 * math.Pi * grid[ext[i]])
+		}
+		barycentricWeights(x, ad)
+
+		// The deviation delta is chosen so that the polynomial through the
+		// points des + (-1)^i * delta / wt has degree r-2, i.e. the error
+		// alternates with equal height on the extremal frequencies.
+		var num, den float64
+		sign := 1.0
+		for i := range x {
+			num += ad[i] * des[ext[i]]
+			den += sign * ad[i] / wt[ext[i]]
+			sign = -sign
+		}
+		delta := -num / den
+		sign = 1.0
+		for i := range y {
+			y[i] = des[ext[i]] + sign*delta/wt[ext[i]]
+			sign = -sign
+		}
+
+		maxErr := 0.0
+		for g := range grid {
+			errs[g] = (interpolate(math.Cos(2*math.Pi*grid[g])) - des[g]) * wt[g]
+			maxErr = math.Max(maxErr, math.Abs(errs[g]))
+		}
+		lower = math.Max(lower, math.Abs(delta))
+		if maxErr < best {
+			best = maxErr
+			copy(bestX, x)
+			copy(bestAD, ad)
+			copy(bestY, y)
+		}
+		if best <= lower*(1+1e-3) || best <= 1e-6*scale {
+			converged = true
+			break
+		}
+
+		newExt := remezExtrema(errs, math.Abs(delta), r)
+		changed := len(newExt) == r
+		if changed {
+			changed = false
+			for i := range ext {
+				if ext[i] != newExt[i] {
+					changed = true
+				}
+			}
+		}
+		if changed {
+			copy(ext, newExt)
+		} else {
+			// Not finding enough extrema happens for bad starting sets, e.g.
+			// symmetric ones that give delta = 0. Exchanging only the point
+			// of the largest error still moves towards the optimum.
+			converged = !remezSingleExchange(ext, errs, delta)
+		}
+	}
+	if !converged {
+		return nil
+	}
+	copy(x, bestX)
+	copy(ad, bestAD)
+	copy(y, bestY)
+
+	// Sample the final approximation at nfcns equally spaced frequencies and
+	// compute the cosine coefficients with an inverse DFT.
+	cn := float64(2*nfcns - 1)
+	a := make([]float64, nfcns)
+	for j := range a {
+		a[j] = interpolate(math.Cos(2 * math.Pi * float64(j) / cn))
+	}
+	alpha := make([]float64, nfcns)
+	for j := range alpha {
+		sum := a[0]
+		for k := 1; k < nfcns; k++ {
+			sum += 2 * a[k] * math.Cos(2*math.Pi*float64(j*k)/cn)
+		}
+		alpha[j] = sum / cn
+		if j > 0 {
+			alpha[j] *= 2
+		}
+	}
+	return alpha
+}
+
+// remezSingleExchange replaces one of the extremal frequencies ext with the
+// grid point of the largest error, keeping the signs of the errors at ext
+// alternating. The error at ext[i] is (-1)^i * delta. If no error is larger
+// than |delta|, the current approximation is optimal and false is returned.
+func remezSingleExchange(ext []int, errs []float64, delta float64) bool {
+	g := 0
+	for i := range errs {
+		if math.Abs(errs[i]) > math.Abs(errs[g]) {
+			g = i
+		}
+	}
+	if math.Abs(errs[g]) <= math.Abs(delta)*(1+1e-9) {
+		return false
+	}
+
+	r := len(ext)
+	positive := errs[g] > 0
+	sameSign := func(i int) bool {
+		return (i%2 == 0) == (delta >= 0) == positive
+	}
+	j := 0
+	for j < r && ext[j] < g {
+		j++
+	}
+	switch {
+	case j == 0 && sameSign(0):
+		ext[0] = g
+	case j == 0:
+		copy(ext[1:], ext[:r-1])
+		ext[0] = g
+	case j == r && sameSign(r-1):
+		ext[r-1] = g
+	case j == r:
+		copy(ext, ext[1:])
+		ext[r-1] = g
+	case sameSign(j - 1):
+		ext[j-1] = g
+	default:
+		ext[j] = g
+	}
+	return true
+}
+
+// barycentricWeights sets ad[i] to 1 / product over j != i of 2*(x[i]-x[j]).
+// The factors are multiplied in an interleaved order so that the intermediate
+// products do not overflow.
+func barycentricWeights(x, ad []float64) {
+	n := len(x)
+	step := (n-2)/15 + 1
+	for k := range x {
+		p := 1.0
+		for l := 0; l < step; l++ {
+			for j := l; j < n; j += step {
+				if j != k {
+					p *= 2 * (x[k] - x[j])
+				}
+			}
+		}
+		ad[k] = 1 / p
+	}
+}
+
+// remezExtrema returns the indices of r alternating local extrema of errs with
+// magnitudes of at least delta. If there are not enough of them, fewer are
+// returned.
+func remezExtrema(errs []float64, delta float64, r int) []int {
+	limit := delta * (1 - 1e-9)
+	var ext []int
+	for g := range errs {
+		e := errs[g]
+		if math.Abs(e) < limit {
+			continue
+		}
+		if g > 0 && (e > 0 && errs[g-1] > e || e < 0 && errs[g-1] < e) {
+			continue
+		}
+		if g+1 < len(errs) && (e > 0 && errs[g+1] > e || e < 0 && errs[g+1] < e) {
+			continue
+		}
+		// Of consecutive extrema with the same sign, keep the larger one.
+		if len(ext) > 0 {
+			last := errs[ext[len(ext)-1]]
+			if last > 0 == (e > 0) {
+				if math.Abs(e) > math.Abs(last) {
+					ext[len(ext)-1] = g
+				}
+				continue
+			}
+		}
+		ext = append(ext, g)
+	}
+	// Drop surplus extrema at the ends, which keeps the alternation intact.
+	for len(ext) > r {
+		if math.Abs(errs[ext[0]]) < math.Abs(errs[ext[len(ext)-1]]) {
+			ext = ext[1:]
+		} else {
+			ext = ext[:len(ext)-1]
+		}
+	}
+	return ext
+}
+
+// remezImpulseResponse converts the cosine coefficients alpha back into the
+// taps of the filter of the given type.
+func remezImpulseResponse(alpha []float64, numTaps, nfcns int, neg, odd bool) []float32 {
+	// a is alpha with 1-based indices and zeros beyond its end, which keeps
+	// the formulas below close to their usual published form.
+	a := make([]float64, nfcns+4)
+	copy(a[1:], alpha)
+	h := make([]float64, nfcns+2)
+	nz := nfcns + 1
+	nm1 := nfcns - 1
+
+	switch {
+	case !neg && odd:
+		for i := 1; i <= nm1; i++ {
+			h[i] = 0.5 * a[nz-i]
+		}
+		h[nfcns] = a[1]
+	case !neg && !odd:
+		h[1] = 0.25 * a[nfcns]
+		for i := 2; i <= nm1; i++ {
+			h[i] = 0.25 * (a[nz-i] + a[nfcns+2-i])
+		}
+		h[nfcns] = 0.5*a[1] + 0.25*a[2]
+	case neg && odd:
+		h[1] = 0.25 * a[nfcns]
+		if nfcns >= 2 {
+			h[2] = 0.25 * a[nm1]
+		}
+		for i := 3; i <= nm1; i++ {
+			h[i] = 0.25 * (a[nz-i] - a[nfcns+3-i])
+		}
+		h[nfcns] = 0.5*a[1] - 0.25*a[3]
+	default:
+		h[1] = 0.25 * a[nfcns]
+		for i := 2; i <= nm1; i++ {
+			h[i] = 0.25 * (a[nz-i] - a[nfcns+2-i])
+		}
+		h[nfcns] = 0.5*a[1] - 0.25*a[2]
+	}
+
+	taps := make([]float32, numTaps)
+	for i := 1; i <= nfcns; i++ {
+		taps[i-1] = float32(h[i])
+		if neg {
+			taps[numTaps-i] = float32(-h[i])
+		} else {
+			taps[numTaps-i] = float32(h[i])
+		}
+	}
+	return taps
+}
@@ -0,0 +1,116 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+// maxDeviation returns the largest deviation of the magnitude response of h
+// from gain in the band from f1 to f2 Hz.
+func maxDeviation(h []float32, f1, f2, gain, sampleRate float64) float64 {
+	var dev float64
+	for i := 0; i <= 200; i++ {
+		f := f1 + (f2-f1)*float64(i)/200
+		dev = math.Max(dev, math.Abs(gainAt(h, f, sampleRate)-gain))
+	}
+	return dev
+}
+
+func TestRemezLowpassIsEquiripple(t *testing.T) {
+	h := Remez(31, []float32{0, 1000, 1500, 5000}, []float32{1, 0}, nil, 10000, RemezBandpass)
+	check.Eq(t, len(h), 31)
+	check.EqEps(t, h, Reverse(h), 1e-7)
+	pass := maxDeviation(h, 0, 1000, 1, 10000)
+	stop := maxDeviation(h, 1500, 5000, 0, 10000)
+	check.EqEps(t, pass/stop, 1, 0.02)
+	check.Eq(t, pass < 0.03, true, pass)
+
+	// It beats a windowed design with the same number of taps.
+	w := FIRLowpass(1250, 10000, Hamming(31, Symmetric))
+	check.Eq(t, maxDeviation(w, 1500, 5000, 0, 10000) > stop, true)
+}
+
+func TestRemezWeightsTradeRipples(t *testing.T) {
+	h := Remez(32, []float32{0, 1000, 1500, 5000}, []float32{1, 0}, []float32{1, 10}, 10000, RemezBandpass)
+	check.Eq(t, len(h), 32)
+	check.EqEps(t, h, Reverse(h), 1e-7)
+	pass := maxDeviation(h, 0, 1000, 1, 10000)
+	stop := maxDeviation(h, 1500, 4999, 0, 10000)
+	check.EqEps(t, pass/stop, 10, 0.2)
+}
+
+func TestRemezBandpass(t *testing.T) {
+	h := Remez(
+		61,
+		[]float32{0, 800, 1000, 2000, 2200, 5000},
+		[]float32{0, 1, 0},
+		nil,
+		10000,
+		RemezBandpass,
+	)
+	check.Eq(t, maxDeviation(h, 1000, 2000, 1, 10000) < 0.05, true)
+	check.Eq(t, maxDeviation(h, 0, 800, 0, 10000) < 0.05, true)
+	check.Eq(t, maxDeviation(h, 2200, 5000, 0, 10000) < 0.05, true)
+}
+
+func TestRemezHilbert(t *testing.T) {
+	h := Remez(31, []float32{500, 4500}, []float32{1}, nil, 10000, RemezHilbert)
+	check.EqEps(t, h, Negative(Reverse(h)), 1e-7)
+	// The taps at even distances from the center vanish.
+	for i := 1; i < len(h); i += 2 {
+		check.EqEps(t, float64(h[i]), 0, 1e-5, i)
+	}
+	check.Eq(t, maxDeviation(h, 500, 4500, 1, 10000) < 0.01, true)
+
+	// The output is the input shifted by 90°.
+	x := cosine(400, 1, 1000, 10000)
+	y := FIRFilter(x, h, ConvolveSame)
+	check.EqEps(t, y[100:300], sine(400, 1, 1000, 10000)[100:300], 0.02)
+}
+
+func TestRemezShortHilbertIsOptimal(t *testing.T) {
+	// The symmetric starting set gives no deviation for 3 taps. The optimal
+	// gain is 2*a*sin(2*pi*f/sampleRate) with a = 1/(1+sin(0.1*pi)), its
+	// errors at the band edges and at 2500 Hz have the same size. The dense
+	// grid misses 2500 Hz, so the result is only close to the optimum.
+	h := Remez(3, []float32{500, 4500}, []float32{1}, nil, 10000, RemezHilbert)
+	check.Eq(t, len(h), 3)
+	a := 1 / (1 + math.Sin(0.1*math.Pi))
+	check.EqEps(t, h, []float32{float32(-a), 0, float32(a)}, 5e-3)
+	check.EqEps(t, maxDeviation(h, 500, 4500, 1, 10000), 1-2*a*math.Sin(0.1*math.Pi), 5e-3)
+}
+
+func TestRemezDifferentiator(t *testing.T) {
+	h := Remez(20, []float32{0, 4000}, []float32{2 * math.Pi}, nil, 10000, RemezDifferentiator)
+	check.EqEps(t, h, Negative(Reverse(h)), 1e-7)
+	for _, f := range []float64{100, 1000, 2000, 3000, 4000} {
+		want := 2 * math.Pi * f / 10000
+		check.EqEps(t, gainAt(h, f, 10000), want, 0.01*want, f, " Hz")
+	}
+}
+
+func TestRemezOddDifferentiator(t *testing.T) {
+	h := Remez(21, []float32{0, 3000}, []float32{2 * math.Pi}, nil, 10000, RemezDifferentiator)
+	check.Eq(t, len(h), 21)
+	check.EqEps(t, float64(h[10]), 0, 1e-7)
+	for _, f := range []float64{500, 1500, 3000} {
+		want := 2 * math.Pi * f / 10000
+		check.EqEps(t, gainAt(h, f, 10000), want, 0.01*want, f, " Hz")
+	}
+}
+
+func TestRemezInvalidParameters(t *testing.T) {
+	bands := []float32{0, 1000, 1500, 5000}
+	gains := []float32{1, 0}
+	check.Eq(t, Remez(0, bands, gains, nil, 10000, RemezBandpass), nil)
+	check.Eq(t, Remez(11, bands[:3], gains, nil, 10000, RemezBandpass), nil)
+	check.Eq(t, Remez(11, bands, gains, []float32{1}, 10000, RemezBandpass), nil)
+	check.Eq(t, Remez(11, []float32{0, 1500, 1000, 5000}, gains, nil, 10000, RemezBandpass), nil)
+	check.Eq(t, Remez(11, []float32{0, 1000, 1500, 6000}, gains, nil, 10000, RemezBandpass), nil)
+
+	// Even length filters cannot pass the Nyquist frequency.
+	check.Eq(t, Remez(12, bands, []float32{0, 1}, nil, 10000, RemezBandpass), nil)
+	check.Eq(t, len(Remez(12, bands, gains, nil, 10000, RemezBandpass)), 12)
+}
@@ -0,0 +1,330 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+)
+
+// MinFilter returns the minimum over every window of width neighboring
+// elements of a. Like for AverageFilter, the resulting array is width-1 smaller
+// than a.
+// If the width is 1 or smaller, a copy of the input array is returned.
+// If width is greater than len(a), a one-element array with the minimum value
+// over a is returned.
+// For an empty input an empty output is returned.
+func MinFilter(a []float32, width int) []float32 {
+	return extremumFilter(a, width, func(x, y float32) bool { return x <= y })
+}
+
+// MaxFilter returns the maximum over every window of width neighboring
+// elements of a, e.g. for a peak-hold envelope. Like for AverageFilter, the
+// resulting array is width-1 smaller than a.
+// If the width is 1 or smaller, a copy of the input array is returned.
+// If width is greater than len(a), a one-element array with the maximum value
+// over a is returned.
+// For an empty input an empty output is returned.
+func MaxFilter(a []float32, width int) []float32 {
+	return extremumFilter(a, width, func(x, y float32) bool { return x >= y })
+}
+
+// extremumFilter keeps the indices of the candidates for the extremum in a
+// deque, ordered by index and by value. Every index enters and leaves the
+// deque once, which makes this O(len(a)). dominates reports whether a new
+// value x makes an older value y obsolete.
+func extremumFilter(a []float32, width int, dominates func(x, y float32) bool) []float32 {
+	if width >= len(a) {
+		width = len(a)
+	}
+
+	if width <= 1 {
+		return Copy(a)
+	}
+
+	b := make([]float32, len(a)-(width-1))
+	var deque []int
+	for i, v := range a {
+		for len(deque) > 0 && dominates(v, a[deque[len(deque)-1]]) {
+			deque = deque[:len(deque)-1]
+		}
+		deque = append(deque, i)
+		if deque[0] <= i-width {
+			deque = deque[1:]
+		}
+		if i >= width-1 {
+			b[i-(width-1)] = a[deque[0]]
+		}
+	}
+	return b
+}
+
+// StdFilter returns the standard deviation over every window of width
+// neighboring elements of a. It is the population standard deviation, i.e. the
+// variance is normalized by width, not width-1. The variance is updated with
+// Welford's method so it does not suffer from cancellation for signals with
+// large offsets. Like for AverageFilter, the resulting array is width-1 smaller
+// than a.
+// If the width is 1 or smaller, an array of zeros with the length of a is
+// returned.
+// If width is greater than len(a), a one-element array with the standard
+// deviation over a is returned.
+// For an empty input an empty output is returned.
+func StdFilter(a []float32, width int) []float32 {
+	if width >= len(a) {
+		width = len(a)
+	}
+
+	if width <= 1 {
+		return make([]float32, len(a))
+	}
+
+	b := make([]float32, len(a)-(width-1))
+	n := float64(width)
+
+	// m2 is the sum of the squared deviations from the mean.
+	var mean, m2 float64
+	for i := 0; i < width; i++ {
+		x := float64(a[i])
+		delta := x - mean
+		mean += delta / float64(i+1)
+		m2 += delta * (x - mean)
+	}
+	b[0] = float32(math.Sqrt(m2 / n))
+
+	for i := 1; i < len(b); i++ {
+		in, out := float64(a[i+width-1]), float64(a[i-1])
+		oldMean := mean
+		mean += (in - out) / n
+		m2 += (in - out) * (in - mean + out - oldMean)
+		if m2 < 0 {
+			// Rounding errors can make m2 slightly negative for constant
+			// signals.
+			m2 = 0
+		}
+		b[i] = float32(math.Sqrt(m2 / n))
+	}
+	return b
+}
+
+// RMSFilter returns the root mean square over every window of width
+// neighboring elements of a. Like for AverageFilter, the resulting array is
+// width-1 smaller than a.
+// If the width is 1 or smaller, the absolute values of a are returned.
+// If width is greater than len(a), a one-element array with the RMS value over
+// a is returned.
+// For an empty input an empty output is returned.
+func RMSFilter(a []float32, width int) []float32 {
+	if width >= len(a) {
+		width = len(a)
+	}
+
+	if width <= 1 {
+		return Abs(a)
+	}
+
+	b := make([]float32, len(a)-(width-1))
+	n := float64(width)
+
+	var sum float64
+	for i := 0; i < width; i++ {
+		sum += float64(a[i]) * float64(a[i])
+	}
+	b[0] = float32(math.Sqrt(sum / n))
+
+	for i := 1; i < len(b); i++ {
+		in, out := float64(a[i+width-1]), float64(a[i-1])
+		sum += in*in - out*out
+		if sum < 0 {
+			sum = 0
+		}
+		b[i] = float32(math.Sqrt(sum / n))
+	}
+	return b
+}
+
+// PercentileFilter returns the p-th percentile, 0 <= p <= 100, over every
+// window of width neighboring elements of a. Percentiles between two samples
+// of the sorted window are interpolated linearly, so p = 0 gives the minimum,
+// p = 100 the maximum and p = 50 the median. Note that for even widths, this
+// median is the average of the two middle elements while MedianFilter uses the
+// upper of them. Like for AverageFilter, the resulting array is width-1 smaller
+// than a.
+// If p is outside the range [0..100], nil is returned.
+// If the width is 1 or smaller, a copy of the input array is returned.
+// If width is greater than len(a), a one-element array with the percentile
+// over a is returned.
+// For an empty input an empty output is returned.
+func PercentileFilter(a []float32, width int, p float32) []float32 {
+	if !(p >= 0 && p <= 100) {
+		return nil
+	}
+
+	if width >= len(a) {
+		width = len(a)
+	}
+
+	if width <= 1 {
+		return Copy(a)
+	}
+
+	pos := float64(p) / 100 * float64(width-1)
+	rank := int(pos)
+	if rank > width-2 {
+		rank = width - 2
+	}
+	frac := float32(pos - float64(rank))
+
+	b := make([]float32, len(a)-(width-1))
+	window := newSlidingRank(a[:width], rank)
+	at := func() float32 {
+		lo, hi := window.value(), window.next()
+		if frac == 0 {
+			return lo
+		}
+		if frac == 1 {
+			return hi
+		}
+		return lo + frac*(hi-lo)
+	}
+	b[0] = at()
+	for i := 1; i < len(b); i++ {
+		window.replace((i-1)%width, a[i+width-1])
+		b[i] = at()
+	}
+	return b
+}
+
+// slidingRank keeps the values of a sliding window so that the value at a
+// fixed rank in the sorted window can be read in constant time and a value
+// can be replaced in O(log(width)) time. The values of rank < rank are kept
+// in a max-heap and the others in a min-heap, the wanted value is the top of
+// the min-heap.
+// Every value occupies a slot, for a sliding window over a signal, sample i
+// goes into slot i%width.
+type slidingRank struct {
+	values []float32
+	// heaps[0] is the max-heap with the small values, heaps[1] the min-heap
+	// with the large values. They hold slots.
+	heaps [2][]int
+	heap  []int // heap of each slot
+	pos   []int // position of each slot in its heap
+}
+
+// newSlidingRank creates a sliding window over the given values, which must
+// not be empty, that tracks the value at the given rank, 0 <= rank <
+// len(values).
+func newSlidingRank(values []float32, rank int) *slidingRank {
+	n := len(values)
+	r := &slidingRank{
+		values: make([]float32, n),
+		heap:   make([]int, n),
+		pos:    make([]int, n),
+	}
+	copy(r.values, values)
+
+	// A sorted array is a valid min-heap, a reversed one a valid max-heap.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return r.values[order[i]] < r.values[order[j]]
+	})
+	for i := rank - 1; i >= 0; i-- {
+		r.push(0, order[i])
+	}
+	for _, s := range order[rank:] {
+		r.push(1, s)
+	}
+	return r
+}
+
+func (r *slidingRank) push(h, slot int) {
+	r.heap[slot] = h
+	r.pos[slot] = len(r.heaps[h])
+	r.heaps[h] = append(r.heaps[h], slot)
+}
+
+// value returns the value at the tracked rank.
+func (r *slidingRank) value() float32 {
+	return r.values[r.heaps[1][0]]
+}
+
+// next returns the value at the rank after the tracked rank. The tracked rank
+// must not be the last one.
+func (r *slidingRank) next() float32 {
+	high := r.heaps[1]
+	next := high[1]
+	if len(high) > 2 && r.values[high[2]] < r.values[next] {
+		next = high[2]
+	}
+	return r.values[next]
+}
+
+// replace sets the value of the given slot to v.
+func (r *slidingRank) replace(slot int, v float32) {
+	r.values[slot] = v
+	r.fix(r.heap[slot], r.pos[slot])
+
+	// Only one value changed, so if the heaps are out of order, exchanging
+	// their tops restores the order.
+	if len(r.heaps[0]) > 0 && r.values[r.heaps[0][0]] > r.values[r.heaps[1][0]] {
+		low, high := r.heaps[0][0], r.heaps[1][0]
+		r.heaps[0][0], r.heaps[1][0] = high, low
+		r.heap[low], r.heap[high] = 1, 0
+		r.down(0, 0)
+		r.down(1, 0)
+	}
+}
+
+// above reports whether slot s belongs above slot t in heap h.
+func (r *slidingRank) above(h, s, t int) bool {
+	if h == 0 {
+		return r.values[s] > r.values[t]
+	}
+	return r.values[s] < r.values[t]
+}
+
+func (r *slidingRank) swap(h, i, j int) {
+	heap := r.heaps[h]
+	heap[i], heap[j] = heap[j], heap[i]
+	r.pos[heap[i]] = i
+	r.pos[heap[j]] = j
+}
+
+func (r *slidingRank) fix(h, i int) {
+	if !r.up(h, i) {
+		r.down(h, i)
+	}
+}
+
+func (r *slidingRank) up(h, i int) bool {
+	heap := r.heaps[h]
+	moved := false
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !r.above(h, heap[i], heap[parent]) {
+			break
+		}
+		r.swap(h, i, parent)
+		i = parent
+		moved = true
+	}
+	return moved
+}
+
+func (r *slidingRank) down(h, i int) {
+	heap := r.heaps[h]
+	for {
+		top := i
+		for _, child := range [2]int{2*i + 1, 2*i + 2} {
+			if child < len(heap) && r.above(h, heap[child], heap[top]) {
+				top = child
+			}
+		}
+		if top == i {
+			return
+		}
+		r.swap(h, i, top)
+		i = top
+	}
+}
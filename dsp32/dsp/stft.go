@@ -0,0 +1,170 @@
+package dsp
+
+import "math"
+
+// STFT returns the short-time Fourier transform of x. Frame i is the one-sided
+// spectrum of the segment of x centered at sample i*hop, multiplied with the
+// window and zero padded to fftLen samples. Samples before the start and after
+// the end of x are treated as zero. There are enough frames so that every
+// sample of x is covered by at least one frame, the last frame is centered at
+// or after the end of x.
+// Each frame has fftLen/2+1 bins. If fftLen is smaller than the window length,
+// the window length is used instead.
+// If x or window are empty or hop <= 0, nil is returned.
+func STFT(x, window []float32, hop, fftLen int) [][]complex64 {
+	n := len(window)
+	if len(x) == 0 || n == 0 || hop <= 0 {
+		return nil
+	}
+	if fftLen < n {
+		fftLen = n
+	}
+
+	plan := NewRealFFTPlan(fftLen)
+	segment := make([]float32, fftLen)
+	frames := make([][]complex64, (len(x)+hop-1)/hop+1)
+	for i := range frames {
+		start := i*hop - n/2
+		for j := 0; j < n; j++ {
+			k := start + j
+			if 0 <= k && k < len(x) {
+				segment[j] = x[k] * window[j]
+			} else {
+				segment[j] = 0
+			}
+		}
+		frames[i] = make([]complex64, plan.Bins())
+		plan.Forward(frames[i], segment)
+	}
+	return frames
+}
+
+// ISTFT returns the signal of the given length with the short-time Fourier
+// transform frames, as returned by STFT with the same window, hop and fftLen.
+// The frames are transformed back, multiplied with the window again and
+// overlap-added. The result is divided by the overlap-added squared window,
+// which makes ISTFT the exact inverse of STFT for all samples where that sum
+// is not zero, see IsNOLA. Samples where it is zero are set to 0.
+// If length <= 0, the window is empty or hop <= 0, nil is returned.
+func ISTFT(frames [][]complex64, window []float32, hop, fftLen, length int) []float32 {
+	n := len(window)
+	if length <= 0 || n == 0 || hop <= 0 {
+		return nil
+	}
+	if fftLen < n {
+		fftLen = n
+	}
+
+	plan := NewRealFFTPlan(fftLen)
+	bins := make([]complex64, plan.Bins())
+	segment := make([]float32, fftLen)
+	sum := make([]float64, length)
+	norm := make([]float64, length)
+	for i, frame := range frames {
+		for k := range bins {
+			bins[k] = 0
+		}
+		copy(bins, frame)
+		plan.Inverse(segment, bins)
+		start := i*hop - n/2
+		for j := 0; j < n; j++ {
+			k := start + j
+			if 0 <= k && k < length {
+				w := float64(window[j])
+				sum[k] += float64(segment[j]) * w
+				norm[k] += w * w
+			}
+		}
+	}
+
+	x := make([]float32, length)
+	limit := 1e-10 * float64(sumOfSquares(window))
+	for i := range x {
+		if norm[i] > limit {
+			x[i] = float32(sum[i] / norm[i])
+		}
+	}
+	return x
+}
+
+// Spectrogram returns the magnitudes of the short-time Fourier transform of x,
+// see STFT for the parameters. magnitude[i][k] is the magnitude of frequency
+// bin k in frame i. times holds the time in seconds of each frame's center and
+// freqs the frequency in Hz of each bin, for x sampled at sampleRate Hz.
+// If x or window are empty or hop <= 0, empty slices are returned.
+func Spectrogram(x []float32, sampleRate float32, window []float32, hop, fftLen int) (times, freqs []float32, magnitude [][]float32) {
+	frames := STFT(x, window, hop, fftLen)
+	if frames == nil {
+		return nil, nil, nil
+	}
+	if fftLen < len(window) {
+		fftLen = len(window)
+	}
+
+	times = make([]float32, len(frames))
+	magnitude = make([][]float32, len(frames))
+	for i := range frames {
+		times[i] = float32(float64(i*hop) / float64(sampleRate))
+		magnitude[i] = Magnitude(frames[i])
+	}
+	return times, SpectrumFrequencies(fftLen, sampleRate), magnitude
+}
+
+// IsCOLA reports whether window satisfies the constant overlap-add condition
+// for the given hop size, i.e. copies of the window shifted by multiples of hop
+// add up to a constant. For such windows, overlap-adding the windowed segments
+// of a signal gives back the signal times a constant.
+// A periodic Hann window satisfies it for hop sizes of a half or a quarter of
+// its length.
+func IsCOLA(window []float32, hop int) bool {
+	if len(window) == 0 || hop <= 0 {
+		return false
+	}
+	sums := overlapAdd(window, hop, false)
+	min, max := sums[0], sums[0]
+	for _, s := range sums {
+		min = math.Min(min, s)
+		max = math.Max(max, s)
+	}
+	return max > 0 && max-min <= 1e-5*max
+}
+
+// IsNOLA reports whether window satisfies the nonzero overlap-add condition
+// for the given hop size, i.e. the squares of copies of the window shifted by
+// multiples of hop add up to a value that is nowhere zero. This is what ISTFT
+// needs to invert STFT exactly.
+func IsNOLA(window []float32, hop int) bool {
+	if len(window) == 0 || hop <= 0 {
+		return false
+	}
+	sums := overlapAdd(window, hop, true)
+	limit := 1e-10 * float64(sumOfSquares(window))
+	for _, s := range sums {
+		if s <= limit {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapAdd returns the sums of all window values (or their squares) that
+// fall on the same position modulo hop.
+func overlapAdd(window []float32, hop int, squared bool) []float64 {
+	sums := make([]float64, hop)
+	for i, w := range window {
+		v := float64(w)
+		if squared {
+			v *= v
+		}
+		sums[i%hop] += v
+	}
+	return sums
+}
+
+func sumOfSquares(a []float32) float32 {
+	var sum float32
+	for _, v := range a {
+		sum += v * v
+	}
+	return sum
+}
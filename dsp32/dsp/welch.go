@@ -0,0 +1,206 @@
+package dsp
+
+// DetrendType selects what is removed from a signal before spectral
+// estimation.
+type DetrendType int
+
+const (
+	// NoDetrend leaves the signal unchanged.
+	NoDetrend DetrendType = iota
+
+	// ConstantDetrend subtracts the mean, which removes the DC component.
+	ConstantDetrend
+
+	// LinearDetrend subtracts the least squares line through the signal,
+	// which removes DC and a linear drift.
+	LinearDetrend
+)
+
+// Detrend returns a copy of a with the trend of the given type removed.
+func Detrend(a []float32, kind DetrendType) []float32 {
+	b := Copy(a)
+	detrendInPlace(b, kind)
+	return b
+}
+
+func detrendInPlace(a []float32, kind DetrendType) {
+	switch kind {
+	case ConstantDetrend:
+		var sum float64
+		for _, v := range a {
+			sum += float64(v)
+		}
+		mean := float32(sum / float64(len(a)))
+		for i := range a {
+			a[i] -= mean
+		}
+	case LinearDetrend:
+		if len(a) < 2 {
+			detrendInPlace(a, ConstantDetrend)
+			return
+		}
+		// Fit a + b*t with t centered around 0 so the two coefficients are
+		// independent.
+		center := float64(len(a)-1) / 2
+		var sum, sumT, sumTT float64
+		for i, v := range a {
+			t := float64(i) - center
+			sum += float64(v)
+			sumT += t * float64(v)
+			sumTT += t * t
+		}
+		mean := sum / float64(len(a))
+		slope := sumT / sumTT
+		for i := range a {
+			a[i] -= float32(mean + slope*(float64(i)-center))
+		}
+	}
+}
+
+// Welch returns the power spectral density of x, sampled at sampleRate Hz, in
+// units of x squared per Hz, estimated with Welch's method: x is split into
+// segments of len(window) samples, where consecutive segments share overlap
+// samples. Each segment is detrended, multiplied with the window and
+// transformed, and the resulting periodograms are averaged. This reduces the
+// variance of the estimate at the cost of frequency resolution.
+// freqs holds the frequency in Hz of each of the len(window)/2+1 bins in psd.
+// overlap is clamped to the range [0, len(window)-1], half the window length is
+// a common choice. If x is shorter than the window, empty slices are returned.
+// If the window is all zeros, nil is returned.
+func Welch(x []float32, sampleRate float32, window []float32, overlap int, detrend DetrendType) (freqs, psd []float32) {
+	pxx, _, _ := welch(x, nil, sampleRate, window, overlap, detrend)
+	if pxx == nil {
+		return nil, nil
+	}
+	psd = make([]float32, len(pxx))
+	for i := range psd {
+		psd[i] = float32(pxx[i])
+	}
+	return SpectrumFrequencies(len(window), sampleRate), psd
+}
+
+// CSD returns the cross power spectral density of x and y, estimated with
+// Welch's method, see Welch for the parameters. The values are the averages of
+// conj(X)*Y over all segments, where X and Y are the transformed segments of x
+// and y, so the phase is that of y relative to x.
+// If x and y have different lengths, the shorter length is used. If the window
+// is all zeros, nil is returned.
+func CSD(x, y []float32, sampleRate float32, window []float32, overlap int, detrend DetrendType) (freqs []float32, csd []complex64) {
+	x, y = sameLength(x, y)
+	_, _, pxy := welch(x, y, sampleRate, window, overlap, detrend)
+	if pxy == nil {
+		return nil, nil
+	}
+	csd = make([]complex64, len(pxy))
+	for i := range csd {
+		csd[i] = complex64(pxy[i])
+	}
+	return SpectrumFrequencies(len(window), sampleRate), csd
+}
+
+// Coherence returns the magnitude squared coherence of x and y, i.e.
+// |Pxy|² / (Pxx * Pyy), estimated with Welch's method, see Welch for the
+// parameters. The values lie in the range [0, 1], where 1 means that y is
+// perfectly linearly related to x at that frequency. Bins where either signal
+// has no power are 0.
+// Note that with a single segment the coherence is always 1, it needs averaging
+// over many segments to be meaningful.
+// If x and y have different lengths, the shorter length is used. If the window
+// is all zeros, nil is returned.
+func Coherence(x, y []float32, sampleRate float32, window []float32, overlap int, detrend DetrendType) (freqs, coherence []float32) {
+	x, y = sameLength(x, y)
+	pxx, pyy, pxy := welch(x, y, sampleRate, window, overlap, detrend)
+	if pxx == nil {
+		return nil, nil
+	}
+	coherence = make([]float32, len(pxx))
+	for i := range coherence {
+		if pxx[i] > 0 && pyy[i] > 0 {
+			re, im := real(pxy[i]), imag(pxy[i])
+			coherence[i] = float32((re*re + im*im) / (pxx[i] * pyy[i]))
+		}
+	}
+	return SpectrumFrequencies(len(window), sampleRate), coherence
+}
+
+func sameLength(x, y []float32) ([]float32, []float32) {
+	if len(x) < len(y) {
+		return x, y[:len(x)]
+	}
+	return x[:len(y)], y
+}
+
+// welch computes the one-sided, averaged and density scaled auto spectra of x
+// and y and their cross spectrum. If y is nil, only pxx is computed. If there
+// is not a single segment or the window is all zeros, all results are nil.
+func welch(x, y []float32, sampleRate float32, window []float32, overlap int, detrend DetrendType) (pxx, pyy []float64, pxy []complex128) {
+	n := len(window)
+	if n == 0 || len(x) < n {
+		return nil, nil, nil
+	}
+	var sumSquares float64
+	for _, w := range window {
+		sumSquares += float64(w) * float64(w)
+	}
+	if sumSquares == 0 {
+		return nil, nil, nil
+	}
+	if overlap < 0 {
+		overlap = 0
+	}
+	if overlap > n-1 {
+		overlap = n - 1
+	}
+	step := n - overlap
+
+	plan := NewRealFFTPlan(n)
+	bins := plan.Bins()
+	segment := make([]float32, n)
+	xSpectrum := make([]complex64, bins)
+	ySpectrum := make([]complex64, bins)
+	transform := func(dst []complex64, src []float32) {
+		copy(segment, src)
+		detrendInPlace(segment, detrend)
+		for i := range segment {
+			segment[i] *= window[i]
+		}
+		plan.Forward(dst, segment)
+	}
+
+	pxx = make([]float64, bins)
+	if y != nil {
+		pyy = make([]float64, bins)
+		pxy = make([]complex128, bins)
+	}
+	count := 0
+	for start := 0; start+n <= len(x); start += step {
+		transform(xSpectrum, x[start:start+n])
+		if y != nil {
+			transform(ySpectrum, y[start:start+n])
+		}
+		for k := range pxx {
+			xk := complex128(xSpectrum[k])
+			pxx[k] += real(xk)*real(xk) + imag(xk)*imag(xk)
+			if y != nil {
+				yk := complex128(ySpectrum[k])
+				pyy[k] += real(yk)*real(yk) + imag(yk)*imag(yk)
+				pxy[k] += complex(real(xk), -imag(xk)) * yk
+			}
+		}
+		count++
+	}
+
+	scale := 1 / (float64(sampleRate) * sumSquares * float64(count))
+	for k := range pxx {
+		s := 2 * scale
+		if k == 0 || 2*k == n {
+			s = scale
+		}
+		pxx[k] *= s
+		if y != nil {
+			pyy[k] *= s
+			pxy[k] *= complex(s, 0)
+		}
+	}
+	return
+}
@@ -0,0 +1,233 @@
+package dsp
+
+import "math/cmplx"
+
+// ZPK describes a digital filter by the zeros and poles of its transfer
+// function and a gain:
+//
+//	H(z) = Gain * (z - Zeros[0]) * (z - Zeros[1]) * ... / ((z - Poles[0]) * ...)
+//
+// For filters with real coefficients, complex zeros and poles come in
+// conjugate pairs.
+type ZPK struct {
+	Zeros []complex64
+	Poles []complex64
+	Gain  float32
+}
+
+// TFToZPK returns the zeros, poles and gain of the transfer function
+//
+//	H(z) = (b[0] + b[1]*z^-1 + b[2]*z^-2 + ...) / (a[0] + a[1]*z^-1 + ...)
+//
+// The shorter of b and a is padded with zeros, so a longer a adds zeros at the
+// origin and a longer b adds poles at the origin. Leading zeros in b, i.e.
+// delays, remove zeros. If a is empty or a[0] is 0, the zero value is returned.
+func TFToZPK(b, a []float32) ZPK {
+	if len(a) == 0 || a[0] == 0 {
+		return ZPK{}
+	}
+	f := tfToZPK(toFloat64s(b), toFloat64s(a))
+	return ZPK{
+		Zeros: tocomplex64s(f.z),
+		Poles: tocomplex64s(f.p),
+		Gain:  float32(f.k),
+	}
+}
+
+func tfToZPK(b, a []float64) zpk {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	num := make([]complex128, n)
+	den := make([]complex128, n)
+	for i, v := range b {
+		num[i] = complex(v, 0)
+	}
+	for i, v := range a {
+		den[i] = complex(v, 0)
+	}
+	for len(num) > 0 && num[0] == 0 {
+		num = num[1:]
+	}
+	if len(num) == 0 {
+		return zpk{p: polyRoots(den)}
+	}
+	return zpk{
+		z: polyRoots(num),
+		p: polyRoots(den),
+		k: real(num[0]) / a[0],
+	}
+}
+
+// ZPKToTF returns the transfer function b/a of the filter f, see TFToZPK. The
+// coefficients are normalized to a[0] = 1. If f has more zeros than poles, it
+// is not causal and a starts with zeros.
+func ZPKToTF(f ZPK) (b, a []float32) {
+	n := len(f.Zeros)
+	if len(f.Poles) > n {
+		n = len(f.Poles)
+	}
+	num := poly(toComplex128s(f.Zeros))
+	den := poly(toComplex128s(f.Poles))
+	b = make([]float32, n+1)
+	a = make([]float32, n+1)
+	for i, v := range num {
+		b[n+1-len(num)+i] = float32(float64(f.Gain) * real(v))
+	}
+	for i, v := range den {
+		a[n+1-len(den)+i] = float32(real(v))
+	}
+	return b, a
+}
+
+// poly returns the coefficients of the monic polynomial with the given roots,
+// starting with the highest power.
+func poly(roots []complex128) []complex128 {
+	c := []complex128{1}
+	for _, r := range roots {
+		c = append(c, 0)
+		for i := len(c) - 1; i > 0; i-- {
+			c[i] -= r * c[i-1]
+		}
+	}
+	return c
+}
+
+// ZPKToSOS groups the zeros and poles of f into second order sections. Complex
+// roots are paired with their conjugates and real roots with each other. The
+// poles closest to the unit circle go into the last section, and each pair of
+// poles is combined with the zeros closest to it, which keeps the sections
+// well-behaved numerically. The gain is applied in the first section. Odd
+// numbers of roots are made up with roots at the origin.
+// The complex zeros and poles must come in conjugate pairs.
+func ZPKToSOS(f ZPK) []Biquad {
+	return zpkToSOS(zpk{
+		z: toComplex128s(f.Zeros),
+		p: toComplex128s(f.Poles),
+		k: float64(f.Gain),
+	})
+}
+
+// SOSToZPK returns the zeros, poles and gain of the cascade of second order
+// sections. Each section contributes two zeros and two poles, first order
+// sections have a zero and a pole at the origin.
+func SOSToZPK(sections []Biquad) ZPK {
+	f := ZPK{Gain: 1}
+	for _, s := range sections {
+		g := TFToZPK([]float32{s.B0, s.B1, s.B2}, []float32{1, s.A1, s.A2})
+		f.Zeros = append(f.Zeros, g.Zeros...)
+		f.Poles = append(f.Poles, g.Poles...)
+		f.Gain *= g.Gain
+	}
+	return f
+}
+
+// TFToSOS converts the transfer function b/a into second order sections, see
+// TFToZPK and ZPKToSOS.
+func TFToSOS(b, a []float32) []Biquad {
+	if len(a) == 0 || a[0] == 0 {
+		return nil
+	}
+	return zpkToSOS(tfToZPK(toFloat64s(b), toFloat64s(a)))
+}
+
+// SOSToTF multiplies out the cascade of second order sections into a single
+// transfer function b/a, see TFToZPK. High order transfer functions are
+// numerically sensitive, prefer filtering with the sections.
+func SOSToTF(sections []Biquad) (b, a []float32) {
+	num, den := []float64{1}, []float64{1}
+	mul := func(p []float64, c0, c1, c2 float32) []float64 {
+		q := make([]float64, len(p)+2)
+		for i, v := range p {
+			q[i] += v * float64(c0)
+			q[i+1] += v * float64(c1)
+			q[i+2] += v * float64(c2)
+		}
+		return q
+	}
+	for _, s := range sections {
+		num = mul(num, s.B0, s.B1, s.B2)
+		den = mul(den, 1, s.A1, s.A2)
+	}
+	b = make([]float32, len(num))
+	a = make([]float32, len(den))
+	for i := range num {
+		b[i] = float32(num[i])
+		a[i] = float32(den[i])
+	}
+	return b, a
+}
+
+// Roots returns the complex roots of the polynomial with the coefficients c,
+// starting with the highest power, e.g. {1, 0, -4} for x² - 4. The roots are
+// not sorted. Leading zeros in c are ignored. If fewer than two coefficients
+// remain, the result is empty.
+func Roots(c []float32) []complex64 {
+	return tocomplex64s(polyRoots(toComplex128s(ToComplex(c))))
+}
+
+// IsStable returns true if all poles of f lie strictly inside the unit circle,
+// i.e. the impulse response of the filter decays.
+func (f ZPK) IsStable() bool {
+	return len(f.UnstablePoles()) == 0
+}
+
+// UnstablePoles returns the poles of f that lie on or outside the unit circle.
+func (f ZPK) UnstablePoles() []complex64 {
+	var outside []complex64
+	for _, p := range f.Poles {
+		if cmplx.Abs(complex128(p)) >= 1-unitCircleTolerance {
+			outside = append(outside, p)
+		}
+	}
+	return outside
+}
+
+// IsMinimumPhase returns true if f is stable and all its zeros lie inside or
+// on the unit circle. Among all filters with the same magnitude response, a
+// minimum phase filter has the smallest delay.
+func (f ZPK) IsMinimumPhase() bool {
+	if !f.IsStable() {
+		return false
+	}
+	for _, z := range f.Zeros {
+		if cmplx.Abs(complex128(z)) > 1+unitCircleTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// unitCircleTolerance absorbs the rounding errors of roots that lie on the
+// unit circle, which depend on the precision of float32.
+var unitCircleTolerance = func() float64 {
+	if float32(1+1e-10) == 1 {
+		return 1e-5
+	}
+	return 1e-9
+}()
+
+func toFloat64s(a []float32) []float64 {
+	f := make([]float64, len(a))
+	for i := range a {
+		f[i] = float64(a[i])
+	}
+	return f
+}
+
+func toComplex128s(a []complex64) []complex128 {
+	c := make([]complex128, len(a))
+	for i := range a {
+		c[i] = complex128(a[i])
+	}
+	return c
+}
+
+func tocomplex64s(a []complex128) []complex64 {
+	c := make([]complex64, len(a))
+	for i := range a {
+		c[i] = complex64(a[i])
+	}
+	return c
+}
@@ -0,0 +1,105 @@
+package dsp
+
+// Biquad holds the coefficients of a second order IIR filter section with the
+// transfer function
+//
+//	H(z) = (B0 + B1*z^-1 + B2*z^-2) / (1 + A1*z^-1 + A2*z^-2)
+//
+// The leading denominator coefficient is normalized to 1. First order sections
+// have B2 = A2 = 0.
+type Biquad struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+}
+
+// BiquadCascade is an IIR filter made of second order sections (SOS) that are
+// applied one after the other. Each section is implemented in direct form II
+// transposed. The filter keeps its state between calls to Process, so a long
+// signal can be filtered in chunks and gives the same result as filtering it
+// at once.
+// Splitting a high order filter into second order sections keeps it
+// numerically stable where a single high order transfer function would not be.
+type BiquadCascade struct {
+	sections []Biquad
+	state    [][2]float64
+}
+
+// NewBiquadCascade creates a filter from the given sections, with its state
+// reset to zero. The sections are copied. Without sections, the filter passes
+// its input through unchanged.
+func NewBiquadCascade(sections []Biquad) *BiquadCascade {
+	s := make([]Biquad, len(sections))
+	copy(s, sections)
+	return &BiquadCascade{
+		sections: s,
+		state:    make([][2]float64, len(s)),
+	}
+}
+
+// Sections returns a copy of the filter's coefficients.
+func (f *BiquadCascade) Sections() []Biquad {
+	s := make([]Biquad, len(f.sections))
+	copy(s, f.sections)
+	return s
+}
+
+// ProcessSample filters the single sample x and returns the output sample.
+func (f *BiquadCascade) ProcessSample(x float64) float64 {
+	v := float64(x)
+	for i, s := range f.sections {
+		st := &f.state[i]
+		y := float64(s.B0)*v + st[0]
+		st[0] = float64(s.B1)*v - float64(s.A1)*y + st[1]
+		st[1] = float64(s.B2)*v - float64(s.A2)*y
+		v = y
+	}
+	return float64(v)
+}
+
+// Process filters the samples in x, continuing from the state that the last
+// call left behind, and returns the filtered samples. x is not modified.
+func (f *BiquadCascade) Process(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for i := range x {
+		y[i] = f.ProcessSample(x[i])
+	}
+	return y
+}
+
+// Reset sets the filter's state back to zero, as if no samples had been
+// processed.
+func (f *BiquadCascade) Reset() {
+	for i := range f.state {
+		f.state[i] = [2]float64{}
+	}
+}
+
+// SetSteadyState sets the filter's state to the state that a constant input
+// of x would settle at, so that a signal starting at x does not cause a
+// transient at its start. Sections with a pole at DC, which never settle, get a
+// zero state.
+func (f *BiquadCascade) SetSteadyState(x float64) {
+	v := float64(x)
+	for i, s := range f.sections {
+		den := 1 + float64(s.A1) + float64(s.A2)
+		if den == 0 {
+			f.state[i] = [2]float64{}
+			continue
+		}
+		// In direct form II transposed, the output is B0*v + state[0], and the
+		// output settles at v times the DC gain of the section.
+		y := v * (float64(s.B0) + float64(s.B1) + float64(s.B2)) / den
+		f.state[i] = [2]float64{
+			y - float64(s.B0)*v,
+			float64(s.B2)*v - float64(s.A2)*y,
+		}
+		v = y
+	}
+}
+
+// IIRFilter returns the signal x filtered with the cascade of second order
+// sections, starting from a zero state. The result has the same length as x.
+// Use a BiquadCascade to filter a signal in chunks.
+func IIRFilter(x []float64, sections []Biquad) []float64 {
+	return NewBiquadCascade(sections).Process(x)
+}
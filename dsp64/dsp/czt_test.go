@@ -0,0 +1,73 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestCZTOnUnitCircleIsDFT(t *testing.T) {
+	for _, n := range []int{1, 5, 16, 30} {
+		x := testSignal(n)
+		w := complex128(complex(math.Cos(2*math.Pi/float64(n)), -math.Sin(2*math.Pi/float64(n))))
+		check.EqEps(t, CZT(x, n, w, 1), naiveDFT(x), 1e-3, "n=", n)
+	}
+}
+
+func TestCZTEvaluatesZTransformOnSpiral(t *testing.T) {
+	x := testSignal(7)
+	a := complex128(complex(0.9, 0.2))
+	w := complex128(complex(1.01, -0.05))
+	X := CZT(x, 12, w, a)
+	check.Eq(t, len(X), 12)
+	for k := range X {
+		z := complex128(a) * cmplxPow(complex128(w), -float64(k))
+		var want complex128
+		for n := range x {
+			want += complex128(x[n]) * cmplxPow(z, -float64(n))
+		}
+		check.EqEps(t, X[k], complex128(want), 1e-3, "k=", k)
+	}
+}
+
+func cmplxPow(z complex128, p float64) complex128 {
+	r := math.Pow(math.Hypot(real(z), imag(z)), p)
+	phi := math.Atan2(imag(z), real(z)) * p
+	return complex(r*math.Cos(phi), r*math.Sin(phi))
+}
+
+func TestCZTEdgeCases(t *testing.T) {
+	check.Eq(t, CZT(testSignal(4), 0, 1, 1), nil)
+	check.Eq(t, CZT(nil, 3, 1, 1), []complex128{0, 0, 0})
+}
+
+func TestZoomFFTEvaluatesDTFTInBand(t *testing.T) {
+	x := realTestSignal(200)
+	freqs, spectrum := ZoomRFFT(x, 10, 12, 100, 21)
+	check.Eq(t, len(freqs), 21)
+	check.Eq(t, freqs[0], 10)
+	check.EqEps(t, freqs[5], 10.5, 1e-5)
+	check.Eq(t, freqs[20], 12)
+	for k := range spectrum {
+		want := complex128(naiveDTFT(x, float64(freqs[k]), 100))
+		check.EqEps(t, spectrum[k], want, 1e-2, "k=", k)
+	}
+}
+
+func TestZoomFFTResolvesToneBetweenBins(t *testing.T) {
+	x := sine(1000, 1, 50.37, 1000)
+	freqs, spectrum := ZoomRFFT(x, 50, 51, 1000, 101)
+	check.EqEps(t, freqs[MaxIndex(Magnitude(spectrum))], 50.37, 1e-4)
+}
+
+func TestZoomFFTWithOneOrNoPoints(t *testing.T) {
+	x := testSignal(10)
+	freqs, spectrum := ZoomFFT(x, 2, 3, 10, 1)
+	check.Eq(t, freqs, []float64{2})
+	check.EqEps(t, spectrum[0], naiveDFT(x)[2], 1e-4)
+
+	freqs, spectrum = ZoomFFT(x, 2, 3, 10, 0)
+	check.Eq(t, freqs, nil)
+	check.Eq(t, spectrum, nil)
+}
@@ -0,0 +1,208 @@
+package dsp
+
+import "math"
+
+// ConvolutionMode selects which part of a convolution is returned.
+type ConvolutionMode int
+
+const (
+	// ConvolveFull returns the complete convolution of length n+m-1 for inputs
+	// of lengths n and m, including the parts where the filter only partially
+	// overlaps the signal.
+	ConvolveFull ConvolutionMode = iota
+
+	// ConvolveSame returns the len(x) values of the full convolution that are
+	// centered on the samples of x. For filters with an odd number of taps and
+	// a symmetric impulse response, e.g. the linear phase designs of this
+	// package, the output is aligned with the input.
+	ConvolveSame
+
+	// ConvolveValid returns only the n-m+1 values where the filter completely
+	// overlaps the signal, like AverageFilter does. If the filter is longer
+	// than the signal, the result is empty.
+	ConvolveValid
+)
+
+// FIRFilter returns the signal x filtered with the FIR filter taps, i.e. the
+// convolution of x and taps. mode selects which part of the convolution is
+// returned, see ConvolutionMode.
+// Long filters are applied via FFT, short ones directly.
+// If x or taps are empty, an empty slice is returned.
+func FIRFilter(x, taps []float64, mode ConvolutionMode) []float64 {
+	return Convolve(x, taps, mode)
+}
+
+// Convolve returns the convolution of a and b, see ConvolutionMode for the
+// length of the result. For ConvolveSame the result has the length of a.
+// If a or b are empty, an empty slice is returned.
+func Convolve(a, b []float64, mode ConvolutionMode) []float64 {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil
+	}
+
+	var start, length int
+	switch mode {
+	case ConvolveSame:
+		start, length = (m-1)/2, n
+	case ConvolveValid:
+		if m > n {
+			return nil
+		}
+		start, length = m-1, n-m+1
+	default:
+		start, length = 0, n+m-1
+	}
+
+	if n <= 64 || m <= 64 {
+		return directConvolution(a, b, start, length)
+	}
+	full := fftConvolution(a, b)
+	return full[start : start+length]
+}
+
+// directConvolution computes the values start to start+length-1 of the full
+// convolution of a and b.
+func directConvolution(a, b []float64, start, length int) []float64 {
+	c := make([]float64, length)
+	for i := range c {
+		k := start + i
+		// Sum over all j with 0 <= j < len(b) and 0 <= k-j < len(a).
+		first := k - len(a) + 1
+		if first < 0 {
+			first = 0
+		}
+		last := k
+		if last > len(b)-1 {
+			last = len(b) - 1
+		}
+		var sum float64
+		for j := first; j <= last; j++ {
+			sum += b[j] * a[k-j]
+		}
+		c[i] = sum
+	}
+	return c
+}
+
+func fftConvolution(a, b []float64) []float64 {
+	n := len(a) + len(b) - 1
+	p := NewRealFFTPlan(nextPowerOfTwo(n))
+	padded := make([]float64, p.Len())
+
+	copy(padded, a)
+	A := make([]complex128, p.Bins())
+	p.Forward(A, padded)
+
+	for i := range padded {
+		padded[i] = 0
+	}
+	copy(padded, b)
+	B := make([]complex128, p.Bins())
+	p.Forward(B, padded)
+
+	for i := range A {
+		A[i] *= B[i]
+	}
+	p.Inverse(padded, A)
+	return padded[:n]
+}
+
+// FIRLowpass designs a linear phase lowpass FIR filter with the windowed sinc
+// method. cutoff is the -6 dB frequency in Hz for signals sampled at
+// sampleRate Hz. The filter has len(window) taps, the window trades transition
+// width (a longer window makes it steeper) and stop band attenuation (e.g. -53
+// dB for Hamming, -74 dB for Blackman, or choose it with Kaiser's beta).
+// The taps are normalized to a gain of 1 at DC.
+// If the window is empty, nil is returned.
+func FIRLowpass(cutoff, sampleRate float64, window []float64) []float64 {
+	if len(window) == 0 {
+		return nil
+	}
+	h := windowedSinc(cutoff, sampleRate, window)
+	return normalizeGain(h, 0)
+}
+
+// FIRHighpass designs a linear phase highpass FIR filter with the windowed
+// sinc method, see FIRLowpass for the parameters. The taps are normalized to a
+// gain of 1 at the Nyquist frequency.
+// A highpass needs an odd number of taps, since an even length filter with a
+// symmetric impulse response always has a zero at the Nyquist frequency. If
+// len(window) is even or zero, nil is returned.
+func FIRHighpass(cutoff, sampleRate float64, window []float64) []float64 {
+	if len(window)%2 == 0 {
+		return nil
+	}
+	h := Negative(windowedSinc(cutoff, sampleRate, window))
+	h[len(h)/2] += window[len(h)/2]
+	return normalizeGain(h, 0.5)
+}
+
+// FIRBandpass designs a linear phase bandpass FIR filter with the windowed sinc
+// method, passing the frequencies between low and high Hz. See FIRLowpass for
+// the other parameters. The taps are normalized to a gain of 1 at the center
+// frequency (low+high)/2.
+// If the window is empty, nil is returned.
+func FIRBandpass(low, high, sampleRate float64, window []float64) []float64 {
+	if len(window) == 0 {
+		return nil
+	}
+	h := Sub(
+		windowedSinc(high, sampleRate, window),
+		windowedSinc(low, sampleRate, window),
+	)
+	return normalizeGain(h, float64((low+high)/2/sampleRate))
+}
+
+// FIRBandstop designs a linear phase bandstop FIR filter with the windowed sinc
+// method, blocking the frequencies between low and high Hz. See FIRLowpass for
+// the other parameters. The taps are normalized to a gain of 1 at DC.
+// Like a highpass, a bandstop needs an odd number of taps. If len(window) is
+// even or zero, nil is returned.
+func FIRBandstop(low, high, sampleRate float64, window []float64) []float64 {
+	if len(window)%2 == 0 {
+		return nil
+	}
+	h := Sub(
+		windowedSinc(low, sampleRate, window),
+		windowedSinc(high, sampleRate, window),
+	)
+	h[len(h)/2] += window[len(h)/2]
+	return normalizeGain(h, 0)
+}
+
+// windowedSinc returns the ideal lowpass impulse response, centered in the
+// window and multiplied with it.
+func windowedSinc(cutoff, sampleRate float64, window []float64) []float64 {
+	fc := float64(cutoff) / float64(sampleRate)
+	center := float64(len(window)-1) / 2
+	h := make([]float64, len(window))
+	for i := range h {
+		t := float64(i) - center
+		var v float64
+		if t == 0 {
+			v = 2 * fc
+		} else {
+			v = math.Sin(2*math.Pi*fc*t) / (math.Pi * t)
+		}
+		h[i] = float64(v) * window[i]
+	}
+	return h
+}
+
+// normalizeGain scales h in place so that its magnitude response at the
+// normalized frequency f (in cycles per sample) is 1, and returns h.
+func normalizeGain(h []float64, f float64) []float64 {
+	var sum complex128
+	for i, v := range h {
+		sum += complex(float64(v), 0) * cmplxExp(-2*math.Pi*f*float64(i))
+	}
+	gain := math.Hypot(real(sum), imag(sum))
+	if gain == 0 {
+		return h
+	}
+	for i := range h {
+		h[i] = float64(float64(h[i]) / gain)
+	}
+	return h
+}
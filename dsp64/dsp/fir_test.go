@@ -0,0 +1,87 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+// gainAt returns the magnitude response of the FIR filter h at freq Hz.
+func gainAt(h []float64, freq, sampleRate float64) float64 {
+	X := naiveDTFT(h, freq, sampleRate)
+	return math.Hypot(real(X), imag(X))
+}
+
+func TestConvolveModes(t *testing.T) {
+	a := []float64{1, 2, 3, 4}
+	b := []float64{1, 10, 100}
+	check.Eq(t, Convolve(a, b, ConvolveFull), []float64{1, 12, 123, 234, 340, 400})
+	check.Eq(t, Convolve(a, b, ConvolveSame), []float64{12, 123, 234, 340})
+	check.Eq(t, Convolve(a, b, ConvolveValid), []float64{123, 234})
+	check.Eq(t, Convolve(b, a, ConvolveValid), nil)
+	check.Eq(t, Convolve(nil, b, ConvolveFull), nil)
+	check.Eq(t, Convolve(a, nil, ConvolveSame), nil)
+}
+
+func TestConvolveValidOfAverageIsAverageFilter(t *testing.T) {
+	x := []float64{1, 5, 2, 8, 3}
+	check.EqEps(t, FIRFilter(x, Repeat(1.0/3, 3), ConvolveValid), AverageFilter(x, 3), 1e-6)
+}
+
+func TestLongConvolutionsUseFFT(t *testing.T) {
+	a := realTestSignal(300)
+	b := noise(100)
+	fast := Convolve(a, b, ConvolveFull)
+	slow := directConvolution(a, b, 0, len(a)+len(b)-1)
+	check.EqEps(t, fast, slow, 1e-4)
+	check.EqEps(t, Convolve(a, b, ConvolveSame), slow[49:349], 1e-4)
+	check.EqEps(t, Convolve(a, b, ConvolveValid), slow[99:300], 1e-4)
+}
+
+func TestFIRLowpass(t *testing.T) {
+	h := FIRLowpass(1000, 10000, Hamming(101, Symmetric))
+	check.Eq(t, len(h), 101)
+	check.EqEps(t, h, Reverse(h), 1e-7)
+	check.EqEps(t, gainAt(h, 0, 10000), 1, 1e-5)
+	check.EqEps(t, gainAt(h, 500, 10000), 1, 0.01)
+	check.EqEps(t, gainAt(h, 1000, 10000), 0.5, 0.01)
+	for _, f := range []float64{1500, 2000, 3000, 5000} {
+		check.Eq(t, gainAt(h, f, 10000) < 0.003, true, f, " Hz")
+	}
+	check.Eq(t, FIRLowpass(1000, 10000, nil), nil)
+}
+
+func TestFIRHighpass(t *testing.T) {
+	h := FIRHighpass(1000, 10000, Blackman(101, Symmetric))
+	check.EqEps(t, gainAt(h, 5000, 10000), 1, 1e-5)
+	check.EqEps(t, gainAt(h, 1000, 10000), 0.5, 0.01)
+	check.Eq(t, gainAt(h, 0, 10000) < 0.001, true)
+	check.Eq(t, FIRHighpass(1000, 10000, Blackman(100, Symmetric)), nil)
+}
+
+func TestFIRBandpass(t *testing.T) {
+	h := FIRBandpass(1000, 2000, 10000, Hamming(151, Symmetric))
+	check.EqEps(t, gainAt(h, 1500, 10000), 1, 1e-5)
+	check.EqEps(t, gainAt(h, 1000, 10000), 0.5, 0.01)
+	check.EqEps(t, gainAt(h, 2000, 10000), 0.5, 0.01)
+	check.Eq(t, gainAt(h, 0, 10000) < 0.003, true)
+	check.Eq(t, gainAt(h, 3000, 10000) < 0.003, true)
+}
+
+func TestFIRBandstop(t *testing.T) {
+	h := FIRBandstop(1000, 2000, 10000, Hamming(151, Symmetric))
+	check.EqEps(t, gainAt(h, 0, 10000), 1, 1e-5)
+	check.EqEps(t, gainAt(h, 5000, 10000), 1, 0.01)
+	check.Eq(t, gainAt(h, 1500, 10000) < 0.003, true)
+	check.Eq(t, FIRBandstop(1000, 2000, 10000, Hamming(150, Symmetric)), nil)
+}
+
+func TestFIRFilterRemovesHighFrequency(t *testing.T) {
+	x := Add(sine(2000, 1, 50, 10000), sine(2000, 1, 3000, 10000))
+	h := FIRLowpass(500, 10000, Blackman(201, Symmetric))
+	y := FIRFilter(x, h, ConvolveSame)
+	check.Eq(t, len(y), len(x))
+	want := sine(2000, 1, 50, 10000)
+	check.EqEps(t, y[200:1800], want[200:1800], 1e-3)
+}
@@ -0,0 +1,131 @@
+package dsp
+
+import "math"
+
+// Goertzel returns the discrete time Fourier transform of x at the single
+// frequency freq Hz, for x sampled at sampleRate Hz:
+//
+//	sum over n of x[n] * exp(-2*pi*i*freq/sampleRate*n)
+//
+// This is the same value that FFT would return for the bin at freq, but freq
+// does not have to lie on a bin. It is much cheaper than an FFT if only a few
+// frequencies are of interest. A sinusoid with a whole number of periods in x
+// has the amplitude 2*|Goertzel(x)|/len(x).
+// For empty x, 0 is returned.
+func Goertzel(x []float64, freq, sampleRate float64) complex128 {
+	return complex128(goertzel(x, goertzelOmega(freq, sampleRate)))
+}
+
+// GoertzelPower returns the squared magnitude of Goertzel(x, freq, sampleRate).
+// It is slightly cheaper to compute since the phase is not needed.
+func GoertzelPower(x []float64, freq, sampleRate float64) float64 {
+	omega := goertzelOmega(freq, sampleRate)
+	s1, s2 := goertzelState(x, omega)
+	c := 2 * math.Cos(omega)
+	return float64(s1*s1 + s2*s2 - c*s1*s2)
+}
+
+func goertzelOmega(freq, sampleRate float64) float64 {
+	return 2 * math.Pi * float64(freq) / float64(sampleRate)
+}
+
+// goertzelState runs the Goertzel resonator over x and returns its last two
+// states.
+func goertzelState(x []float64, omega float64) (s1, s2 float64) {
+	c := 2 * math.Cos(omega)
+	for _, v := range x {
+		s1, s2 = float64(v)+c*s1-s2, s1
+	}
+	return
+}
+
+func goertzel(x []float64, omega float64) complex128 {
+	if len(x) == 0 {
+		return 0
+	}
+	s1, s2 := goertzelState(x, omega)
+	// The resonator output is the transform referenced to the last sample,
+	// rotating it back references it to the first sample.
+	y := complex(s1-math.Cos(omega)*s2, math.Sin(omega)*s2)
+	return y * cmplxExp(-omega*float64(len(x)-1))
+}
+
+// SlidingGoertzel computes the Goertzel value of the last n samples for every
+// new sample, in constant time per sample. Use it to track the level of a tone
+// continuously, e.g. for tone detection on a sample stream.
+type SlidingGoertzel struct {
+	omega   float64
+	rotate  complex128 // exp(i*omega)
+	newest  complex128 // exp(-i*omega*(n-1))
+	history []float64
+	next    int
+	value   complex128
+}
+
+// NewSlidingGoertzel creates a sliding Goertzel detector for frequency freq Hz
+// over windows of n samples, sampled at sampleRate Hz. The window starts out
+// filled with zeros. If n < 1, a window of 1 sample is used.
+func NewSlidingGoertzel(n int, freq, sampleRate float64) *SlidingGoertzel {
+	if n < 1 {
+		n = 1
+	}
+	omega := goertzelOmega(freq, sampleRate)
+	return &SlidingGoertzel{
+		omega:   omega,
+		rotate:  cmplxExp(omega),
+		newest:  cmplxExp(-omega * float64(n-1)),
+		history: make([]float64, n),
+	}
+}
+
+// Add pushes x into the window, dropping the oldest sample, and returns the
+// updated value, see Value.
+func (g *SlidingGoertzel) Add(x float64) complex128 {
+	oldest := g.history[g.next]
+	g.history[g.next] = x
+	g.next++
+	if g.next == len(g.history) {
+		// The history is in order now. Recomputing the value from scratch
+		// every n samples keeps rounding errors from accumulating.
+		g.next = 0
+		g.value = goertzel(g.history, g.omega)
+	} else {
+		g.value = g.rotate*(g.value-complex(float64(oldest), 0)) +
+			complex(float64(x), 0)*g.newest
+	}
+	return complex128(g.value)
+}
+
+// Process calls Add for every sample in x and returns all values.
+func (g *SlidingGoertzel) Process(x []float64) []complex128 {
+	values := make([]complex128, len(x))
+	for i := range x {
+		values[i] = g.Add(x[i])
+	}
+	return values
+}
+
+// Value returns the Goertzel value of the current window, i.e. the same as
+// Goertzel would return for the last n samples.
+func (g *SlidingGoertzel) Value() complex128 {
+	return complex128(g.value)
+}
+
+// Power returns the squared magnitude of Value.
+func (g *SlidingGoertzel) Power() float64 {
+	re, im := real(g.value), imag(g.value)
+	return float64(re*re + im*im)
+}
+
+// Reset fills the window with zeros again.
+func (g *SlidingGoertzel) Reset() {
+	for i := range g.history {
+		g.history[i] = 0
+	}
+	g.next = 0
+	g.value = 0
+}
+
+func cmplxExp(theta float64) complex128 {
+	return complex(math.Cos(theta), math.Sin(theta))
+}
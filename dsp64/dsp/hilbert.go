@@ -0,0 +1,53 @@
+package dsp
+
+import "math"
+
+// AnalyticSignal returns the analytic signal of x, i.e. the complex signal
+// whose real part is x and whose imaginary part is the Hilbert transform of x.
+// It is computed with an FFT by removing all negative frequencies and doubling
+// the positive ones, which treats x as periodic. Apply a window or pad the
+// signal if the ends of x do not fit together.
+func AnalyticSignal(x []float64) []complex128 {
+	n := len(x)
+	if n == 0 {
+		return nil
+	}
+	p := NewFFTPlan(n)
+	a := ToComplex(x)
+	p.Forward(a, a)
+	// DC and, for even n, the Nyquist frequency stay as they are.
+	for k := 1; 2*k < n; k++ {
+		a[k] *= 2
+		a[n-k] = 0
+	}
+	p.Inverse(a, a)
+	return a
+}
+
+// Hilbert returns the Hilbert transform of x, which shifts the phase of every
+// frequency component by -90°, e.g. turning cosines into sines. See
+// AnalyticSignal.
+func Hilbert(x []float64) []float64 {
+	return ImagParts(AnalyticSignal(x))
+}
+
+// Envelope returns the instantaneous amplitude of x, i.e. the magnitude of its
+// analytic signal. For an amplitude modulated carrier this is the modulating
+// signal.
+func Envelope(x []float64) []float64 {
+	return Magnitude(AnalyticSignal(x))
+}
+
+// InstantaneousPhase returns the unwrapped phase of the analytic signal of x,
+// in radians.
+func InstantaneousPhase(x []float64) []float64 {
+	return Unwrap(Phase(AnalyticSignal(x)))
+}
+
+// InstantaneousFrequency returns the instantaneous frequency in Hz of x,
+// sampled at sampleRate Hz. It is the derivative of the instantaneous phase, so
+// like Derivative, the result is one item smaller than x. Result 0 is the
+// frequency between samples 0 and 1 and so on.
+func InstantaneousFrequency(x []float64, sampleRate float64) []float64 {
+	return Scale(Derivative(InstantaneousPhase(x)), float64(float64(sampleRate)/(2*math.Pi)))
+}
@@ -0,0 +1,198 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+// sosResponse returns the complex frequency response of the second order
+// sections at freq Hz.
+func sosResponse(sections []Biquad, freq, sampleRate float64) complex128 {
+	z1 := cmplxExp(-2 * math.Pi * freq / sampleRate)
+	z2 := z1 * z1
+	h := complex(1, 0)
+	for _, s := range sections {
+		b := complex(float64(s.B0), 0) + complex(float64(s.B1), 0)*z1 + complex(float64(s.B2), 0)*z2
+		a := 1 + complex(float64(s.A1), 0)*z1 + complex(float64(s.A2), 0)*z2
+		h *= b / a
+	}
+	return h
+}
+
+// sosGainDB returns the magnitude response in dB of the second order sections
+// at freq Hz.
+func sosGainDB(sections []Biquad, freq, sampleRate float64) float64 {
+	return 20 * math.Log10(cmplx.Abs(sosResponse(sections, freq, sampleRate)))
+}
+
+// sosPolynomials multiplies out the numerators and denominators of the
+// sections.
+func sosPolynomials(sections []Biquad) (b, a []float64) {
+	b, a = []float64{1}, []float64{1}
+	mul := func(p []float64, c0, c1, c2 float64) []float64 {
+		q := make([]float64, len(p)+2)
+		for i, v := range p {
+			q[i] += v * float64(c0)
+			q[i+1] += v * float64(c1)
+			q[i+2] += v * float64(c2)
+		}
+		return q
+	}
+	for _, s := range sections {
+		b = mul(b, s.B0, s.B1, s.B2)
+		a = mul(a, 1, s.A1, s.A2)
+	}
+	return b, a
+}
+
+func TestButterworthMatchesKnownCoefficients(t *testing.T) {
+	sos := Butterworth(2, Lowpass, 2, 0.5)
+	check.Eq(t, len(sos), 1)
+	check.EqEps(t, float64(sos[0].B0), 0.29289322, 1e-6)
+	check.EqEps(t, float64(sos[0].B1), 0.58578644, 1e-6)
+	check.EqEps(t, float64(sos[0].B2), 0.29289322, 1e-6)
+	check.EqEps(t, float64(sos[0].A1), 0, 1e-6)
+	check.EqEps(t, float64(sos[0].A2), 0.17157288, 1e-6)
+
+	b, a := sosPolynomials(Butterworth(4, Lowpass, 2, 0.2))
+	check.EqEps(t, b[:5], []float64{0.00482434, 0.01929737, 0.02894606, 0.01929737, 0.00482434}, 1e-6)
+	check.EqEps(t, a[:5], []float64{1, -2.36951301, 2.31398841, -1.05466541, 0.18737949}, 1e-5)
+}
+
+func TestButterworthLowpass(t *testing.T) {
+	sos := Butterworth(4, Lowpass, 48000, 1000)
+	check.Eq(t, len(sos), 2)
+	check.EqEps(t, sosGainDB(sos, 0, 48000), 0, 1e-4)
+	check.EqEps(t, sosGainDB(sos, 1000, 48000), -3.0103, 1e-3)
+	// 24 dB per octave
+	check.EqEps(t, sosGainDB(sos, 2000, 48000), -24.1, 0.2)
+}
+
+func TestButterworthOddOrderHighpass(t *testing.T) {
+	sos := Butterworth(3, Highpass, 10000, 1000)
+	check.Eq(t, len(sos), 2)
+	check.EqEps(t, sosGainDB(sos, 5000, 10000), 0, 1e-4)
+	check.EqEps(t, sosGainDB(sos, 1000, 10000), -3.0103, 1e-3)
+	check.Eq(t, sosGainDB(sos, 100, 10000) < -55, true)
+}
+
+func TestButterworthBandpassAndBandstop(t *testing.T) {
+	bp := Butterworth(2, Bandpass, 10000, 1000, 2000)
+	check.Eq(t, len(bp), 2)
+	center := math.Atan(math.Sqrt(math.Tan(math.Pi*0.1)*math.Tan(math.Pi*0.2))) / math.Pi * 10000
+	check.EqEps(t, sosGainDB(bp, center, 10000), 0, 1e-4)
+	check.EqEps(t, sosGainDB(bp, 1000, 10000), -3.0103, 1e-3)
+	check.EqEps(t, sosGainDB(bp, 2000, 10000), -3.0103, 1e-3)
+	check.Eq(t, sosGainDB(bp, 200, 10000) < -25, true)
+
+	bs := Butterworth(2, Bandstop, 10000, 1000, 2000)
+	check.EqEps(t, sosGainDB(bs, 0, 10000), 0, 1e-4)
+	check.EqEps(t, sosGainDB(bs, 5000, 10000), 0, 1e-4)
+	check.EqEps(t, sosGainDB(bs, 1000, 10000), -3.0103, 1e-3)
+	check.Eq(t, sosGainDB(bs, center, 10000) < -60, true)
+}
+
+func TestChebyshev1(t *testing.T) {
+	for _, order := range []int{4, 5} {
+		sos := Chebyshev1(order, 1, Lowpass, 10000, 1000)
+		for f := 0.0; f < 1000; f += 10 {
+			g := sosGainDB(sos, f, 10000)
+			check.Eq(t, g < 1e-4 && g > -1.0001, true, order, f)
+		}
+		check.EqEps(t, sosGainDB(sos, 1000, 10000), -1, 1e-3)
+		check.Eq(t, sosGainDB(sos, 2000, 10000) < -30, true)
+	}
+	hp := Chebyshev1(4, 0.5, Highpass, 10000, 1000)
+	check.EqEps(t, sosGainDB(hp, 1000, 10000), -0.5, 1e-3)
+}
+
+func TestChebyshev2(t *testing.T) {
+	for _, order := range []int{4, 5} {
+		sos := Chebyshev2(order, 40, Lowpass, 10000, 1000)
+		check.EqEps(t, sosGainDB(sos, 0, 10000), 0, 1e-4)
+		check.EqEps(t, sosGainDB(sos, 1000, 10000), -40, 1e-3)
+		for f := 1000.0; f <= 5000; f += 10 {
+			check.Eq(t, sosGainDB(sos, f, 10000) < -39.999, true, order, f)
+		}
+	}
+}
+
+func TestElliptic(t *testing.T) {
+	for _, order := range []int{1, 2, 3, 4, 5, 6} {
+		sos := Elliptic(order, 1, 40, Lowpass, 10000, 1000)
+		check.Eq(t, len(sos), (order+1)/2)
+		for f := 0.0; f < 1000; f += 10 {
+			g := sosGainDB(sos, f, 10000)
+			check.Eq(t, g < 1e-4 && g > -1.0001, true, order, f)
+		}
+		check.EqEps(t, sosGainDB(sos, 1000, 10000), -1, 1e-3)
+	}
+
+	// The stop band starts close to the pass band edge.
+	sos := Elliptic(5, 1, 60, Lowpass, 10000, 1000)
+	for f := 1600.0; f <= 5000; f += 10 {
+		check.Eq(t, sosGainDB(sos, f, 10000) < -59.999, true, f)
+	}
+
+	bs := Elliptic(4, 0.5, 50, Bandstop, 10000, 1000, 2000)
+	check.EqEps(t, sosGainDB(bs, 1000, 10000), -0.5, 1e-3)
+	check.EqEps(t, sosGainDB(bs, 2000, 10000), -0.5, 1e-3)
+	check.Eq(t, sosGainDB(bs, 1450, 10000) < -49.999, true)
+}
+
+func TestBessel(t *testing.T) {
+	for _, order := range []int{1, 2, 4, 7} {
+		sos := Bessel(order, Lowpass, 48000, 1000)
+		check.EqEps(t, sosGainDB(sos, 0, 48000), 0, 1e-4)
+		check.EqEps(t, sosGainDB(sos, 1000, 48000), -3.0103, 1e-3, order)
+	}
+
+	// The group delay is nearly constant in the pass band, i.e. the phase is
+	// linear.
+	sos := Bessel(6, Lowpass, 48000, 1000)
+	delay := func(f float64) float64 {
+		df := 1.0
+		p1 := cmplx.Phase(sosResponse(sos, f-df, 48000))
+		p2 := cmplx.Phase(sosResponse(sos, f+df, 48000))
+		return -(p2 - p1) / (2 * math.Pi * 2 * df)
+	}
+	d0 := delay(10)
+	for _, f := range []float64{100, 200, 300, 400} {
+		check.EqEps(t, delay(f)/d0, 1, 0.01, f)
+	}
+}
+
+func TestIIRDesignInvalidParameters(t *testing.T) {
+	check.Eq(t, Butterworth(0, Lowpass, 10000, 1000), nil)
+	check.Eq(t, Butterworth(2, Lowpass, 10000), nil)
+	check.Eq(t, Butterworth(2, Lowpass, 10000, 1000, 2000), nil)
+	check.Eq(t, Butterworth(2, Bandpass, 10000, 1000), nil)
+	check.Eq(t, Butterworth(2, Bandpass, 10000, 2000, 1000), nil)
+	check.Eq(t, Butterworth(2, Lowpass, 10000, 5000), nil)
+	check.Eq(t, Butterworth(2, Lowpass, 10000, 0), nil)
+	check.Eq(t, Chebyshev1(2, 0, Lowpass, 10000, 1000), nil)
+	check.Eq(t, Chebyshev2(2, 0, Lowpass, 10000, 1000), nil)
+	check.Eq(t, Elliptic(2, 1, 1, Lowpass, 10000, 1000), nil)
+}
+
+func TestPolyRoots(t *testing.T) {
+	// (x-1)(x+2)(x-3) = x³ - 2x² - 5x + 6
+	r := polyRoots([]complex128{1, -2, -5, 6})
+	re := []float64{real(r[0]), real(r[1]), real(r[2])}
+	sort.Float64s(re)
+	check.EqEps(t, re, []float64{-2, 1, 3}, 1e-12)
+	for _, v := range r {
+		check.EqEps(t, imag(v), 0, 1e-12)
+	}
+
+	// x² + 1 and a root at 0
+	r = polyRoots([]complex128{2, 0, 2, 0})
+	check.Eq(t, len(r), 3)
+	check.EqEps(t, cmplx.Abs(r[0]), 0, 1e-12)
+	check.EqEps(t, cmplx.Abs(r[1]*r[2]-1), 0, 1e-12)
+	check.EqEps(t, cmplx.Abs(r[1]+r[2]), 0, 1e-12)
+}
@@ -0,0 +1,115 @@
+package dsp
+
+import "math"
+
+// Notch designs a second order IIR filter that removes the frequency freq, in
+// Hz, and passes all others with a gain of 1. q is the quality factor, the
+// ratio of freq to the -3 dB bandwidth of the notch, higher values give
+// narrower notches. The filter has a single section, use it with IIRFilter,
+// IIRFiltFilt or a BiquadCascade.
+// If freq is not between 0 and sampleRate/2 or q <= 0, nil is returned.
+func Notch(freq, q, sampleRate float64) []Biquad {
+	if !validNotch(freq, q, sampleRate) {
+		return nil
+	}
+	c, g := notchParameters(float64(freq), float64(q), float64(sampleRate))
+	return []Biquad{{
+		B0: float64(g),
+		B1: float64(-2 * g * c),
+		B2: float64(g),
+		A1: float64(-2 * g * c),
+		A2: float64(2*g - 1),
+	}}
+}
+
+// Peak designs a second order IIR filter that passes the frequency freq, in
+// Hz, with a gain of 1 and attenuates all others, it is the complement of the
+// Notch with the same parameters. q is the quality factor, the ratio of freq to
+// the -3 dB bandwidth of the peak. The filter has a single section, use it with
+// IIRFilter, IIRFiltFilt or a BiquadCascade.
+// If freq is not between 0 and sampleRate/2 or q <= 0, nil is returned.
+func Peak(freq, q, sampleRate float64) []Biquad {
+	if !validNotch(freq, q, sampleRate) {
+		return nil
+	}
+	c, g := notchParameters(float64(freq), float64(q), float64(sampleRate))
+	return []Biquad{{
+		B0: float64(1 - g),
+		B2: float64(g - 1),
+		A1: float64(-2 * g * c),
+		A2: float64(2*g - 1),
+	}}
+}
+
+// Comb designs a cascade of notches at the fundamental frequency and its
+// first harmonics, in Hz, e.g. to remove mains hum and its harmonics. For
+// harmonics = 0, only the fundamental is removed, otherwise also the multiples
+// 2*fundamental to (harmonics+1)*fundamental. Multiples at or above the Nyquist
+// frequency sampleRate/2 are left out.
+// All notches have the same -3 dB bandwidth fundamental/q, so q is the quality
+// factor of the notch at the fundamental.
+// If fundamental is not between 0 and sampleRate/2, harmonics < 0 or q <= 0,
+// nil is returned.
+func Comb(fundamental float64, harmonics int, q, sampleRate float64) []Biquad {
+	if harmonics < 0 || !validNotch(fundamental, q, sampleRate) {
+		return nil
+	}
+	var sections []Biquad
+	for k := 1; k <= harmonics+1; k++ {
+		freq := float64(k) * fundamental
+		if freq >= sampleRate/2 {
+			break
+		}
+		sections = append(sections, Notch(freq, float64(k)*q, sampleRate)...)
+	}
+	return sections
+}
+
+// NotchFilter returns a filtered with the Notch of the given parameters. The
+// filter starts in the steady state for the first sample so that a DC offset
+// does not cause a transient. The result has the same length as a.
+// If the parameters are invalid, nil is returned.
+func NotchFilter(a []float64, freq, q, sampleRate float64) []float64 {
+	return steadyIIRFilter(a, Notch(freq, q, sampleRate))
+}
+
+// PeakFilter returns a filtered with the Peak of the given parameters. The
+// result has the same length as a.
+// If the parameters are invalid, nil is returned.
+func PeakFilter(a []float64, freq, q, sampleRate float64) []float64 {
+	return steadyIIRFilter(a, Peak(freq, q, sampleRate))
+}
+
+// CombFilter returns a filtered with the Comb of the given parameters. The
+// filter starts in the steady state for the first sample so that a DC offset
+// does not cause a transient. The result has the same length as a.
+// If the parameters are invalid, nil is returned.
+func CombFilter(a []float64, fundamental float64, harmonics int, q, sampleRate float64) []float64 {
+	return steadyIIRFilter(a, Comb(fundamental, harmonics, q, sampleRate))
+}
+
+func validNotch(freq, q, sampleRate float64) bool {
+	return sampleRate > 0 && freq > 0 && freq < sampleRate/2 && q > 0
+}
+
+// notchParameters returns the cosine of the center frequency and the gain
+// factor 1/(1+tan(bandwidth/2)), both in radians per sample, of which the
+// notch and peak coefficients are made.
+func notchParameters(freq, q, sampleRate float64) (c, g float64) {
+	w := 2 * math.Pi * freq / sampleRate
+	bandwidth := w / q
+	return math.Cos(w), 1 / (1 + math.Tan(bandwidth/2))
+}
+
+// steadyIIRFilter filters a with the sections, starting in the steady state
+// for a[0]. Nil sections give nil.
+func steadyIIRFilter(a []float64, sections []Biquad) []float64 {
+	if sections == nil {
+		return nil
+	}
+	f := NewBiquadCascade(sections)
+	if len(a) > 0 {
+		f.SetSteadyState(a[0])
+	}
+	return f.Process(a)
+}
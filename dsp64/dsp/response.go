@@ -0,0 +1,215 @@
+package dsp
+
+import "math"
+
+// FrequencyGrid returns n frequencies in Hz, evenly spaced from 0 up to but
+// not including the Nyquist frequency sampleRate/2, for evaluating frequency
+// responses. If n <= 0 the returned slice is empty.
+func FrequencyGrid(n int, sampleRate float64) []float64 {
+	if n <= 0 {
+		return nil
+	}
+	f := make([]float64, n)
+	for i := range f {
+		f[i] = float64(float64(i) * float64(sampleRate) / float64(2*n))
+	}
+	return f
+}
+
+// FreqResponse returns the complex frequency response of the filter with the
+// transfer function
+//
+//	H(z) = (b[0] + b[1]*z^-1 + b[2]*z^-2 + ...) / (a[0] + a[1]*z^-1 + ...)
+//
+// at the frequencies freqs in Hz, for signals sampled at sampleRate Hz. For FIR
+// filters, pass the taps as b and nil as a.
+// Use Magnitude, MagnitudeDB and PhaseResponse to evaluate the result.
+func FreqResponse(b, a []float64, freqs []float64, sampleRate float64) []complex128 {
+	h := make([]complex128, len(freqs))
+	for i, f := range freqs {
+		h[i] = complex128(transferAt(b, a, omega(f, sampleRate)))
+	}
+	return h
+}
+
+// SOSFreqResponse returns the complex frequency response of the cascade of
+// second order sections at the frequencies freqs in Hz, for signals sampled at
+// sampleRate Hz.
+func SOSFreqResponse(sections []Biquad, freqs []float64, sampleRate float64) []complex128 {
+	h := make([]complex128, len(freqs))
+	for i, f := range freqs {
+		h[i] = complex128(sosTransferAt(sections, omega(f, sampleRate)))
+	}
+	return h
+}
+
+// MagnitudeDB returns the magnitudes of the complex values in h in dB, i.e.
+// 20*log10(|h|). Zeros give -Inf.
+func MagnitudeDB(h []complex128) []float64 {
+	m := make([]float64, len(h))
+	for i := range m {
+		abs := math.Hypot(float64(real(h[i])), float64(imag(h[i])))
+		m[i] = float64(20 * math.Log10(abs))
+	}
+	return m
+}
+
+// PhaseResponse returns the unwrapped phase of the complex values in h in
+// radians, i.e. Unwrap(Phase(h)).
+func PhaseResponse(h []complex128) []float64 {
+	return Unwrap(Phase(h))
+}
+
+// GroupDelay returns the group delay of the filter with the transfer function
+// b/a, see FreqResponse, at the frequencies freqs in Hz. The group delay is the
+// negative derivative of the phase over the angular frequency, i.e. the delay
+// of the envelope of a narrow band signal. It is given in samples, divide it by
+// sampleRate for seconds. At zeros or poles of the response on the unit circle
+// the group delay is undefined and 0 is returned.
+func GroupDelay(b, a []float64, freqs []float64, sampleRate float64) []float64 {
+	d := make([]float64, len(freqs))
+	for i, f := range freqs {
+		d[i] = float64(groupDelayAt(b, a, omega(f, sampleRate)))
+	}
+	return d
+}
+
+// SOSGroupDelay returns the group delay of the cascade of second order
+// sections, see GroupDelay.
+func SOSGroupDelay(sections []Biquad, freqs []float64, sampleRate float64) []float64 {
+	d := make([]float64, len(freqs))
+	for i, f := range freqs {
+		d[i] = float64(sosGroupDelayAt(sections, omega(f, sampleRate)))
+	}
+	return d
+}
+
+// PhaseDelay returns the phase delay of the filter with the transfer function
+// b/a, see FreqResponse, at the frequencies freqs in Hz. The phase delay is the
+// negative unwrapped phase divided by the angular frequency, i.e. the delay of
+// a sinusoid of that frequency. It is given in samples, divide it by
+// sampleRate for seconds. At 0 Hz, the group delay is returned, which is the
+// limit of the phase delay.
+// The phase is unwrapped along freqs, which should therefore be increasing and
+// start at or close to 0.
+func PhaseDelay(b, a []float64, freqs []float64, sampleRate float64) []float64 {
+	return phaseDelay(freqs, sampleRate,
+		func(w float64) complex128 { return transferAt(b, a, w) },
+		func(w float64) float64 { return groupDelayAt(b, a, w) },
+	)
+}
+
+// SOSPhaseDelay returns the phase delay of the cascade of second order
+// sections, see PhaseDelay.
+func SOSPhaseDelay(sections []Biquad, freqs []float64, sampleRate float64) []float64 {
+	return phaseDelay(freqs, sampleRate,
+		func(w float64) complex128 { return sosTransferAt(sections, w) },
+		func(w float64) float64 { return sosGroupDelayAt(sections, w) },
+	)
+}
+
+func phaseDelay(
+	freqs []float64,
+	sampleRate float64,
+	response func(w float64) complex128,
+	groupDelay func(w float64) float64,
+) []float64 {
+	d := make([]float64, len(freqs))
+	var last, offset float64
+	for i, f := range freqs {
+		w := omega(f, sampleRate)
+		h := response(w)
+		p := math.Atan2(imag(h), real(h))
+		if i > 0 {
+			offset -= 2 * math.Pi * math.Floor((p-last+math.Pi)/(2*math.Pi))
+		}
+		last = p
+		if w == 0 {
+			d[i] = float64(groupDelay(w))
+		} else {
+			d[i] = float64(-(p + offset) / w)
+		}
+	}
+	return d
+}
+
+// omega returns the angular frequency in radians per sample.
+func omega(freq, sampleRate float64) float64 {
+	return 2 * math.Pi * float64(freq) / float64(sampleRate)
+}
+
+// polyAt returns the sum over n of c[n]*exp(-i*w*n).
+func polyAt(c []float64, w float64) complex128 {
+	var sum complex128
+	for n, v := range c {
+		sum += complex(float64(v), 0) * cmplxExp(-w*float64(n))
+	}
+	return sum
+}
+
+func transferAt(b, a []float64, w float64) complex128 {
+	h := polyAt(b, w)
+	if len(a) > 0 {
+		h /= polyAt(a, w)
+	}
+	return h
+}
+
+func sosTransferAt(sections []Biquad, w float64) complex128 {
+	h := complex(1, 0)
+	for _, s := range sections {
+		h *= transferAt([]float64{s.B0, s.B1, s.B2}, []float64{1, s.A1, s.A2}, w)
+	}
+	return h
+}
+
+// polyDelayAt returns the group delay of the polynomial c in z^-1, i.e. the
+// real part of (sum over n of n*c[n]*z^-n) / (sum over n of c[n]*z^-n). At a
+// zero of the polynomial the delay is undefined and ok is false. Values that
+// are tiny compared to the coefficients count as zeros. An empty polynomial
+// stands for 1, like a nil denominator does.
+func polyDelayAt(c []float64, w float64) (delay float64, ok bool) {
+	if len(c) == 0 {
+		return 0, true
+	}
+	var num, den complex128
+	var norm float64
+	for n, v := range c {
+		t := complex(float64(v), 0) * cmplxExp(-w*float64(n))
+		num += complex(float64(n), 0) * t
+		den += t
+		norm += float64(v) * float64(v)
+	}
+	if real(den)*real(den)+imag(den)*imag(den) <= 1e-20*norm {
+		return 0, false
+	}
+	return real(num / den), true
+}
+
+// groupDelayAt returns the group delay of b/a, or 0 at zeros and poles on the
+// unit circle.
+func groupDelayAt(b, a []float64, w float64) float64 {
+	d, ok := transferDelayAt(b, a, w)
+	if !ok {
+		return 0
+	}
+	return d
+}
+
+func transferDelayAt(b, a []float64, w float64) (float64, bool) {
+	db, okb := polyDelayAt(b, w)
+	da, oka := polyDelayAt(a, w)
+	return db - da, okb && oka
+}
+
+func sosGroupDelayAt(sections []Biquad, w float64) float64 {
+	var d float64
+	for _, s := range sections {
+		ds, ok := transferDelayAt([]float64{s.B0, s.B1, s.B2}, []float64{1, s.A1, s.A2}, w)
+		if !ok {
+			return 0
+		}
+		d += ds
+	}
+	return d
+}
@@ -0,0 +1,120 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestFrequencyGrid(t *testing.T) {
+	check.Eq(t, FrequencyGrid(4, 8), []float64{0, 1, 2, 3})
+	check.Eq(t, FrequencyGrid(0, 8), nil)
+}
+
+func TestFreqResponseOfFIR(t *testing.T) {
+	taps := []float64{0.5, 0.3, 0.2}
+	freqs := []float64{0, 100, 1000, 2500, 5000}
+	h := FreqResponse(taps, nil, freqs, 10000)
+	for i, f := range freqs {
+		want := naiveDTFT(taps, float64(f), 10000)
+		check.EqEps(t, float64(real(h[i])), real(want), 1e-6)
+		check.EqEps(t, float64(imag(h[i])), imag(want), 1e-6)
+	}
+	check.EqEps(t, float64(real(h[0])), 1, 1e-6)
+}
+
+func TestFreqResponseOfTransferFunction(t *testing.T) {
+	// y[n] = x[n] + 0.5*y[n-1] has the DC gain 2 and the Nyquist gain 2/3.
+	h := FreqResponse([]float64{1}, []float64{1, -0.5}, []float64{0, 5000}, 10000)
+	check.EqEps(t, float64(real(h[0])), 2, 1e-6)
+	check.EqEps(t, float64(real(h[1])), 2.0/3, 1e-6)
+	check.EqEps(t, float64(imag(h[1])), 0, 1e-6)
+
+	// An unnormalized a[0] scales the response.
+	h = FreqResponse([]float64{1}, []float64{2, -1}, []float64{0}, 10000)
+	check.EqEps(t, float64(real(h[0])), 1, 1e-6)
+}
+
+func TestSOSFreqResponseMatchesTransferFunction(t *testing.T) {
+	sos := Chebyshev1(5, 1, Lowpass, 10000, 1000)
+	b64, a64 := sosPolynomials(sos)
+	b, a := make([]float64, len(b64)), make([]float64, len(a64))
+	for i := range b {
+		b[i], a[i] = float64(b64[i]), float64(a64[i])
+	}
+	freqs := FrequencyGrid(50, 10000)
+	check.EqEps(t, SOSFreqResponse(sos, freqs, 10000), FreqResponse(b, a, freqs, 10000), 1e-3)
+	check.EqEps(t, SOSGroupDelay(sos, freqs, 10000), GroupDelay(b, a, freqs, 10000), 1e-2)
+}
+
+func TestMagnitudeDBAndPhaseResponse(t *testing.T) {
+	check.Eq(t, MagnitudeDB([]complex128{1, -10, 0.1i}), []float64{0, 20, -20})
+	check.Eq(t, math.IsInf(float64(MagnitudeDB([]complex128{0})[0]), -1), true)
+
+	// A pure delay of 3 samples has a linear phase of -3*w.
+	freqs := FrequencyGrid(20, 10000)
+	p := PhaseResponse(FreqResponse([]float64{0, 0, 0, 1}, nil, freqs, 10000))
+	for i, f := range freqs {
+		check.EqEps(t, float64(p[i]), -3*2*math.Pi*float64(f)/10000, 1e-5)
+	}
+}
+
+func TestGroupAndPhaseDelayOfLinearPhaseFIR(t *testing.T) {
+	taps := FIRLowpass(1000, 10000, Hamming(21, Symmetric))
+	freqs := FrequencyGrid(10, 10000)[:3]
+	check.EqEps(t, GroupDelay(taps, nil, freqs, 10000), Repeat(10, 3), 1e-4)
+	check.EqEps(t, PhaseDelay(taps, nil, freqs, 10000), Repeat(10, 3), 1e-4)
+}
+
+func TestGroupDelayOfOnePole(t *testing.T) {
+	// The group delay of 1/(1 - p*z^-1) is
+	// (p*cos(w) - p²) / (1 - 2*p*cos(w) + p²).
+	const p = 0.8
+	freqs := []float64{0, 500, 1000, 2500, 5000}
+	d := GroupDelay([]float64{1}, []float64{1, -p}, freqs, 10000)
+	for i, f := range freqs {
+		c := math.Cos(2 * math.Pi * float64(f) / 10000)
+		check.EqEps(t, float64(d[i]), (p*c-p*p)/(1-2*p*c+p*p), 1e-5)
+	}
+}
+
+func TestSOSGroupDelayIsPhaseDerivative(t *testing.T) {
+	sos := Butterworth(4, Lowpass, 10000, 1000)
+	for _, f := range []float64{100, 500, 1000, 2000} {
+		df := 0.01
+		p1 := PhaseResponse(SOSFreqResponse(sos, []float64{float64(f - df), float64(f + df)}, 10000))
+		want := -float64(p1[1]-p1[0]) / (2 * math.Pi * 2 * df / 10000)
+		got := SOSGroupDelay(sos, []float64{float64(f)}, 10000)[0]
+		check.EqEps(t, float64(got), want, 0.05*want, f)
+	}
+}
+
+func TestSOSPhaseDelay(t *testing.T) {
+	sos := Butterworth(2, Lowpass, 10000, 1000)
+	freqs := []float64{0, 10, 1000}
+	d := SOSPhaseDelay(sos, freqs, 10000)
+	// At low frequencies, phase and group delay agree.
+	check.EqEps(t, d[0], SOSGroupDelay(sos, freqs[:1], 10000)[0], 1e-5)
+	check.EqEps(t, d[1], d[0], 1e-2)
+	// A 2nd order Butterworth filter shifts the phase by -90° at the cutoff.
+	check.EqEps(t, float64(d[2]), (math.Pi/2)/(2*math.Pi*0.1), 1e-4)
+}
+
+func TestGroupDelayAtZerosAndSmallCoefficients(t *testing.T) {
+	// 1 + z^-1 has a zero at the Nyquist frequency.
+	freqs := []float64{1000, 5000}
+	d := GroupDelay([]float64{1, 1}, []float64{1, -0.5}, freqs, 10000)
+	check.Eq(t, d[1], float64(0))
+	sos := []Biquad{{B0: 1, B1: 1, A1: -0.5}}
+	check.EqEps(t, SOSGroupDelay(sos, freqs, 10000), d, 1e-5)
+
+	// The scale of the coefficients does not matter.
+	b := []float64{0.5, 0.3, 0.2}
+	check.EqEps(t,
+		GroupDelay(Scale(b, 1e-15), nil, freqs, 10000),
+		GroupDelay(b, nil, freqs, 10000),
+		1e-4,
+	)
+	check.Eq(t, GroupDelay(b, nil, freqs, 10000)[0] != 0, true)
+}
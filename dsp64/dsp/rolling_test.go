@@ -0,0 +1,109 @@
+package dsp
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestMinAndMaxFilter(t *testing.T) {
+	a := []float64{3, 1, 4, 1, 5, 9, 2, 6}
+	check.Eq(t, MinFilter(a, 3), []float64{1, 1, 1, 1, 2, 2})
+	check.Eq(t, MaxFilter(a, 3), []float64{4, 4, 5, 9, 9, 9})
+	check.Eq(t, MaxFilter(a, 2), []float64{3, 4, 4, 5, 9, 9, 6})
+	check.Eq(t, MinFilter(a, 100), []float64{1})
+	check.Eq(t, MaxFilter(a, 100), []float64{9})
+	check.Eq(t, MinFilter(a, 1), a)
+	check.Eq(t, MaxFilter(nil, 3), nil)
+
+	// Compare to the minimum and maximum of every window.
+	x := noise(200)
+	for _, width := range []int{2, 5, 17, 200} {
+		min, max := MinFilter(x, width), MaxFilter(x, width)
+		check.Eq(t, len(min), len(x)-width+1)
+		for i := range min {
+			check.Eq(t, min[i], MinValue(x[i:i+width]), width, i)
+			check.Eq(t, max[i], MaxValue(x[i:i+width]), width, i)
+		}
+	}
+}
+
+func TestStdFilter(t *testing.T) {
+	check.EqEps(t, StdFilter([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8), []float64{2}, 1e-6)
+	check.EqEps(t, StdFilter([]float64{1, 3, 1, 1}, 2), []float64{1, 1, 0}, 1e-6)
+	check.Eq(t, StdFilter([]float64{1, 2, 3}, 1), []float64{0, 0, 0})
+	check.Eq(t, StdFilter(nil, 3), []float64{})
+
+	// A large offset does not change the result.
+	x := noise(300)
+	const offset = 1e4
+	shifted := AddOffset(x, offset)
+	std := StdFilter(x, 25)
+	check.EqEps(t, StdFilter(shifted, 25), std, 2e-3)
+	for i := range std {
+		var sum, sum2 float64
+		for _, v := range x[i : i+25] {
+			sum += float64(v)
+		}
+		mean := sum / 25
+		for _, v := range x[i : i+25] {
+			sum2 += (float64(v) - mean) * (float64(v) - mean)
+		}
+		check.EqEps(t, float64(std[i]), math.Sqrt(sum2/25), 1e-5)
+	}
+
+	// Constant signals have no deviation.
+	check.Eq(t, StdFilter(Repeat(0.1, 50), 7), Repeat(0, 44))
+}
+
+func TestRMSFilter(t *testing.T) {
+	check.EqEps(t, RMSFilter([]float64{3, -4, 0, 0}, 2), []float64{
+		float64(math.Sqrt(12.5)), float64(math.Sqrt(8)), 0,
+	}, 1e-6)
+	check.Eq(t, RMSFilter([]float64{-1, 2}, 1), []float64{1, 2})
+	check.Eq(t, RMSFilter(nil, 2), []float64{})
+
+	// A sine over whole periods has the RMS amp/sqrt(2).
+	x := sine(1000, 2, 50, 1000)
+	check.EqEps(t, RMSFilter(x, 100), Repeat(float64(math.Sqrt2), 901), 1e-4)
+}
+
+func TestPercentileFilter(t *testing.T) {
+	a := []float64{5, 1, 4, 2, 3}
+	check.Eq(t, PercentileFilter(a, 5, 0), []float64{1})
+	check.Eq(t, PercentileFilter(a, 5, 100), []float64{5})
+	check.Eq(t, PercentileFilter(a, 5, 25), []float64{2})
+	check.Eq(t, PercentileFilter(a, 5, 60), []float64{3.4})
+	check.Eq(t, PercentileFilter(a, 2, 50), []float64{3, 2.5, 3, 2.5})
+	check.Eq(t, PercentileFilter(a, 1, 50), a)
+	check.Eq(t, PercentileFilter(nil, 3, 50), []float64{})
+	check.Eq(t, PercentileFilter(a, 3, -1), nil)
+	check.Eq(t, PercentileFilter(a, 3, 101), nil)
+
+	// Compare to sorting every window.
+	x := noise(300)
+	for i := range x {
+		x[i] = float64(math.Floor(float64(x[i]) * 8))
+	}
+	for _, width := range []int{2, 3, 10, 51} {
+		for _, p := range []float64{0, 10, 33, 50, 90, 100} {
+			got := PercentileFilter(x, width, p)
+			buf := make([]float64, width)
+			for i := range got {
+				copy(buf, x[i:])
+				sort.Sort(floats(buf))
+				pos := float64(p) / 100 * float64(width-1)
+				k := int(pos)
+				want := float64(buf[k])
+				if k+1 < width {
+					want += (pos - float64(k)) * float64(buf[k+1]-buf[k])
+				}
+				check.EqEps(t, float64(got[i]), want, 1e-5, width, p, i)
+			}
+		}
+	}
+	check.Eq(t, PercentileFilter(x, 9, 0), MinFilter(x, 9))
+	check.Eq(t, PercentileFilter(x, 9, 100), MaxFilter(x, 9))
+}
@@ -0,0 +1,177 @@
+package dsp
+
+import "math"
+
+// SavitzkyGolay smooths a with a Savitzky-Golay filter. For every sample, a
+// polynomial of the given order is fitted to the width samples around it by
+// least squares and the value of the polynomial at the sample is returned. If
+// deriv > 0, the deriv-th derivative of the polynomial is returned instead,
+// scaled for samples that are spacing apart, e.g. deriv = 1 gives the slope
+// per second for spacing = 1/sampleRate. Unlike Derivative, this does not
+// amplify noise.
+// Higher orders preserve narrow peaks better, wider windows smooth more.
+// The result has the same length as a. Near the ends, the polynomial is fitted
+// to the first or last width samples and evaluated at the samples there. If a
+// has less than width samples, a single polynomial is fitted to all of them.
+// width must be odd and greater than order. If the parameters are invalid,
+// i.e. width < 1, width is even, order < 0, order >= width or deriv < 0, nil is
+// returned. If deriv > order, the result is all zeros.
+func SavitzkyGolay(a []float64, width, order, deriv int, spacing float64) []float64 {
+	if !validSavitzkyGolay(width, order, deriv) {
+		return nil
+	}
+	n := len(a)
+	y := make([]float64, n)
+	if n == 0 || deriv > order {
+		return y
+	}
+
+	w := width
+	if n < w {
+		w = n
+		if order > w-1 {
+			order = w - 1
+		}
+		if deriv > order {
+			return y
+		}
+	}
+	scale := math.Pow(float64(spacing), float64(deriv))
+	fit := savitzkyGolayFit(w, order)
+
+	// The samples from first to last are filtered with the centered window.
+	half := (width - 1) / 2
+	first, last := 0, -1
+	if w == width {
+		// Away from the ends, the filter is a convolution with the weights
+		// for the center of the window.
+		weights := fit.weights(half, deriv)
+		taps := make([]float64, w)
+		for j := range taps {
+			taps[w-1-j] = float64(weights[j] / scale)
+		}
+		copy(y[half:], Convolve(a, taps, ConvolveValid))
+		first, last = half, n-1-half
+	}
+
+	for i := range y {
+		if i >= first && i <= last {
+			continue
+		}
+		start := 0
+		if i > last {
+			start = n - w
+		}
+		weights := fit.weights(i-start, deriv)
+		var sum float64
+		for j, v := range weights {
+			sum += v * float64(a[start+j])
+		}
+		y[i] = float64(sum / scale)
+	}
+	return y
+}
+
+// SavitzkyGolayCoefficients returns the taps of the Savitzky-Golay filter with
+// the given parameters, see SavitzkyGolay, for use with FIRFilter and
+// ConvolveSame. Filtering like this gives the same result as SavitzkyGolay,
+// except for the first and last width/2 samples.
+// If the parameters are invalid, nil is returned.
+func SavitzkyGolayCoefficients(width, order, deriv int, spacing float64) []float64 {
+	if !validSavitzkyGolay(width, order, deriv) {
+		return nil
+	}
+	taps := make([]float64, width)
+	if deriv > order {
+		return taps
+	}
+	scale := math.Pow(float64(spacing), float64(deriv))
+	weights := savitzkyGolayFit(width, order).weights((width-1)/2, deriv)
+	for j := range taps {
+		taps[width-1-j] = float64(weights[j] / scale)
+	}
+	return taps
+}
+
+func validSavitzkyGolay(width, order, deriv int) bool {
+	return width >= 1 && width%2 == 1 && order >= 0 && order < width && deriv >= 0
+}
+
+// polyFit holds the least squares solution for fitting a polynomial to the
+// samples of a window. The polynomial is in u = (j-center)/radius for the
+// sample index j in the window, which keeps the problem well conditioned.
+type polyFit struct {
+	center, radius float64
+	// g maps the samples to the polynomial coefficients, g[k][j] is the
+	// weight of sample j for the coefficient of u^k.
+	g [][]float64
+}
+
+func savitzkyGolayFit(width, order int) polyFit {
+	center := float64(width-1) / 2
+	radius := math.Max(center, 1)
+
+	// Solve the normal equations (V^T*V) * g = V^T with the Vandermonde matrix
+	// V[j][k] = u_j^k by Gaussian elimination with partial pivoting.
+	m := order + 1
+	rows := make([][]float64, m)
+	for k := range rows {
+		rows[k] = make([]float64, m+width)
+		for j := 0; j < width; j++ {
+			u := (float64(j) - center) / radius
+			rows[k][m+j] = math.Pow(u, float64(k))
+			for l := 0; l < m; l++ {
+				rows[k][l] += math.Pow(u, float64(k+l))
+			}
+		}
+	}
+	for col := 0; col < m; col++ {
+		pivot := col
+		for r := col + 1; r < m; r++ {
+			if math.Abs(rows[r][col]) > math.Abs(rows[pivot][col]) {
+				pivot = r
+			}
+		}
+		rows[col], rows[pivot] = rows[pivot], rows[col]
+		for r := 0; r < m; r++ {
+			if r == col {
+				continue
+			}
+			f := rows[r][col] / rows[col][col]
+			for c := col; c < len(rows[r]); c++ {
+				rows[r][c] -= f * rows[col][c]
+			}
+		}
+	}
+	g := make([][]float64, m)
+	for k := range g {
+		g[k] = make([]float64, width)
+		for j := range g[k] {
+			g[k][j] = rows[k][m+j] / rows[k][k]
+		}
+	}
+	return polyFit{center: center, radius: radius, g: g}
+}
+
+// weights returns the weights of the samples that give the deriv-th derivative
+// of the fitted polynomial at the window index t, per sample.
+func (f polyFit) weights(t, deriv int) []float64 {
+	u := (float64(t) - f.center) / f.radius
+	w := make([]float64, len(f.g[0]))
+	for k := deriv; k < len(f.g); k++ {
+		// The deriv-th derivative of u^k is k!/(k-deriv)! * u^(k-deriv).
+		c := math.Pow(u, float64(k-deriv))
+		for i := k - deriv + 1; i <= k; i++ {
+			c *= float64(i)
+		}
+		for j := range w {
+			w[j] += c * f.g[k][j]
+		}
+	}
+	// Convert from derivatives over u to derivatives over the sample index.
+	s := math.Pow(f.radius, float64(deriv))
+	for j := range w {
+		w[j] /= s
+	}
+	return w
+}
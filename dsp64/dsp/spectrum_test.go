@@ -0,0 +1,117 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func sine(n int, amplitude, freq, sampleRate float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = float64(amplitude * math.Sin(2*math.Pi*freq*float64(i)/sampleRate))
+	}
+	return x
+}
+
+func noise(n int) []float64 {
+	// This is a simple linear congruential generator so the tests are
+	// deterministic.
+	x := make([]float64, n)
+	state := uint32(12345)
+	for i := range x {
+		state = state*1664525 + 1013904223
+		x[i] = float64(state)/(1<<32) - 0.5
+	}
+	return x
+}
+
+func TestSpectrumFrequencies(t *testing.T) {
+	check.Eq(t, SpectrumFrequencies(4, 100), []float64{0, 25, 50})
+	check.Eq(t, SpectrumFrequencies(5, 100), []float64{0, 20, 40})
+	check.Eq(t, SpectrumFrequencies(0, 100), nil)
+}
+
+func TestSpectrumOfEmptySignalIsEmpty(t *testing.T) {
+	f, v := Spectrum(nil, 100, nil, PeakAmplitude)
+	check.Eq(t, f, nil)
+	check.Eq(t, v, nil)
+}
+
+func TestSpectrumAmplitudesAreCorrectedForWindow(t *testing.T) {
+	x := AddOffset(sine(1024, 2, 50, 1024), 0.5)
+	for _, window := range [][]float64{nil, Hann(1024, Periodic), FlatTop(1024, Periodic)} {
+		f, peak := Spectrum(x, 1024, window, PeakAmplitude)
+		check.Eq(t, len(f), 513)
+		check.Eq(t, f[50], 50)
+		check.EqEps(t, peak[50], 2, 1e-4)
+		check.EqEps(t, peak[0], 0.5, 1e-4)
+
+		_, rms := Spectrum(x, 1024, window, RMSAmplitude)
+		check.EqEps(t, rms[50], math.Sqrt2, 1e-4)
+		check.EqEps(t, rms[0], 0.5, 1e-4)
+
+		_, power := Spectrum(x, 1024, window, PowerSpectrum)
+		check.EqEps(t, power[50], 2, 1e-4)
+		check.EqEps(t, power[0], 0.25, 1e-4)
+	}
+}
+
+func TestSpectrumAtNyquistFrequency(t *testing.T) {
+	x := []float64{3, -3, 3, -3, 3, -3, 3, -3}
+	_, peak := Spectrum(x, 8, nil, PeakAmplitude)
+	check.EqEps(t, peak, []float64{0, 0, 0, 0, 3}, 1e-5)
+}
+
+func TestPowerSpectralDensitySumsToMeanSquare(t *testing.T) {
+	x := noise(1000)
+	var meanSquare float64
+	for _, v := range x {
+		meanSquare += v * v
+	}
+	meanSquare /= float64(len(x))
+
+	sampleRate := float64(500)
+	binWidth := sampleRate / float64(len(x))
+	_, psd := Spectrum(x, sampleRate, nil, PowerSpectralDensity)
+	var sum float64
+	for _, v := range psd {
+		sum += v * binWidth
+	}
+	check.EqEps(t, sum, meanSquare, 1e-4)
+
+	// With a window, the noise is corrected with the ENBW so the total is
+	// approximately the same.
+	_, psd = Spectrum(x, sampleRate, Hann(len(x), Periodic), PowerSpectralDensity)
+	sum = 0
+	for _, v := range psd {
+		sum += v * binWidth
+	}
+	check.EqEps(t, sum, meanSquare, 0.05*float64(meanSquare))
+}
+
+func TestSpectrumWithWrongWindowLengthReturnsNil(t *testing.T) {
+	freqs, values := Spectrum([]float64{1, 2, 3}, 1, []float64{1, 1}, PeakAmplitude)
+	check.Eq(t, freqs, nil)
+	check.Eq(t, values, nil)
+}
+
+func TestSpectrumWithZeroWindowReturnsNil(t *testing.T) {
+	x := []float64{1, 2, 3, 4}
+	for _, scaling := range []SpectrumScaling{PeakAmplitude, PowerSpectralDensity} {
+		freqs, values := Spectrum(x, 1, make([]float64, 4), scaling)
+		check.Eq(t, freqs, nil)
+		check.Eq(t, values, nil)
+	}
+	_, values := Spectrum(x, 1, []float64{1, -1, 1, -1}, RMSAmplitude)
+	check.Eq(t, values, nil)
+}
+
+func TestSpectrumValuesCanBeConvertedToDecibels(t *testing.T) {
+	check.Eq(t, PeakAmplitude.ToDB([]float64{1, 10, 0.1}, 1), []float64{0, 20, -20})
+	check.Eq(t, RMSAmplitude.ToDB([]float64{2}, 2), []float64{0})
+	check.Eq(t, PowerSpectrum.ToDB([]float64{1, 10, 100}, 0), []float64{0, 10, 20})
+	check.Eq(t, PowerSpectralDensity.ToDB([]float64{1e-3}, 1e-3), []float64{0})
+	check.Eq(t, math.IsInf(float64(PowerSpectrum.ToDB([]float64{0}, 1)[0]), -1), true)
+}
@@ -0,0 +1,75 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestSTFTFramesAreCenteredAtMultiplesOfHop(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	frames := STFT(x, []float64{1, 1, 1, 1}, 2, 4)
+	// Frames are centered at 0, 2, 4 and 6.
+	check.Eq(t, len(frames), 4)
+	check.EqEps(t, frames[0], RFFT([]float64{0, 0, 1, 2}), 1e-6)
+	check.EqEps(t, frames[1], RFFT([]float64{1, 2, 3, 4}), 1e-6)
+	check.EqEps(t, frames[2], RFFT([]float64{3, 4, 5, 0}), 1e-6)
+	check.EqEps(t, frames[3], RFFT([]float64{5, 0, 0, 0}), 1e-6)
+}
+
+func TestSTFTZeroPadsFrames(t *testing.T) {
+	frames := STFT([]float64{1, 2, 3}, []float64{1, 1}, 1, 8)
+	check.Eq(t, len(frames[0]), 5)
+	check.EqEps(t, frames[1], RFFT([]float64{1, 2, 0, 0, 0, 0, 0, 0}), 1e-6)
+}
+
+func TestSTFTOfEmptyInputIsEmpty(t *testing.T) {
+	check.Eq(t, len(STFT(nil, Hann(4, Periodic), 2, 4)), 0)
+	check.Eq(t, len(STFT([]float64{1}, nil, 2, 4)), 0)
+	check.Eq(t, len(STFT([]float64{1}, Hann(4, Periodic), 0, 4)), 0)
+}
+
+func TestISTFTInvertsSTFT(t *testing.T) {
+	x := noise(1000)
+	tests := []struct {
+		window []float64
+		hop    int
+		fftLen int
+	}{
+		{Hann(64, Periodic), 32, 64},
+		{Hann(64, Periodic), 16, 128},
+		{Hann(64, Symmetric), 20, 64},
+		{Kaiser(101, 8, Symmetric), 25, 101},
+		{Repeat(1, 50), 50, 50},
+	}
+	for i, test := range tests {
+		frames := STFT(x, test.window, test.hop, test.fftLen)
+		y := ISTFT(frames, test.window, test.hop, test.fftLen, len(x))
+		check.EqEps(t, y, x, 1e-5, "test ", i)
+	}
+}
+
+func TestSpectrogram(t *testing.T) {
+	x := sine(1024, 1, 100, 1000)
+	times, freqs, mag := Spectrogram(x, 1000, Hann(100, Periodic), 50, 200)
+	check.Eq(t, len(times), len(mag))
+	check.Eq(t, times[2], 0.1)
+	check.Eq(t, len(freqs), 101)
+	check.Eq(t, freqs[20], 100)
+	check.Eq(t, MaxIndex(mag[10]), 20)
+	check.EqEps(t, mag[10][20], 25, 1e-3)
+}
+
+func TestCOLAAndNOLA(t *testing.T) {
+	check.Eq(t, IsCOLA(Hann(64, Periodic), 32), true)
+	check.Eq(t, IsCOLA(Hann(64, Periodic), 16), true)
+	check.Eq(t, IsCOLA(Hann(64, Periodic), 24), false)
+	check.Eq(t, IsCOLA(Hann(64, Symmetric), 32), false)
+	check.Eq(t, IsCOLA(Repeat(1, 10), 5), true)
+	check.Eq(t, IsCOLA(nil, 5), false)
+
+	check.Eq(t, IsNOLA(Hann(64, Periodic), 24), true)
+	check.Eq(t, IsNOLA(Hann(64, Periodic), 64), false)
+	check.Eq(t, IsNOLA(Repeat(1, 10), 11), false)
+	check.Eq(t, IsNOLA(Repeat(1, 10), 0), false)
+}
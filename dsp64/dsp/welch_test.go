@@ -0,0 +1,107 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestDetrend(t *testing.T) {
+	check.Eq(t, Detrend([]float64{1, 2, 3}, NoDetrend), []float64{1, 2, 3})
+	check.Eq(t, Detrend([]float64{1, 2, 6}, ConstantDetrend), []float64{-2, -1, 3})
+	check.EqEps(t, Detrend([]float64{1, 3, 5, 7}, LinearDetrend), []float64{0, 0, 0, 0}, 1e-6)
+	check.EqEps(t, Detrend([]float64{1, 4, 5, 8}, LinearDetrend), []float64{-0.2, 0.6, -0.6, 0.2}, 1e-6)
+	check.Eq(t, Detrend([]float64{5}, LinearDetrend), []float64{0})
+	check.Eq(t, Detrend(nil, LinearDetrend), nil)
+}
+
+func TestDetrendDoesNotModifyInput(t *testing.T) {
+	a := []float64{1, 2, 6}
+	Detrend(a, ConstantDetrend)
+	check.Eq(t, a, []float64{1, 2, 6})
+}
+
+func TestWelchWithOneSegmentIsPeriodogram(t *testing.T) {
+	x := noise(64)
+	window := Hann(64, Periodic)
+	f1, psd1 := Welch(x, 10, window, 0, NoDetrend)
+	f2, psd2 := Spectrum(x, 10, window, PowerSpectralDensity)
+	check.Eq(t, f1, f2)
+	check.EqEps(t, psd1, psd2, 1e-6)
+}
+
+func TestWelchOfWhiteNoiseIsFlat(t *testing.T) {
+	// Uniform noise in [-0.5, 0.5] has a variance of 1/12, spread evenly over
+	// the frequencies from 0 to half the sample rate.
+	sampleRate := float64(1000)
+	x := noise(100000)
+	f, psd := Welch(x, sampleRate, Hann(256, Periodic), 128, ConstantDetrend)
+	check.Eq(t, len(f), 129)
+	check.Eq(t, f[128], 500)
+	level := 1.0 / 12 / 500
+	// Removing the mean also removes some power from the first bins next to
+	// DC because of the window's main lobe width.
+	for i := 2; i < len(psd)-1; i++ {
+		check.EqEps(t, psd[i], level, 0.15*level, "bin ", i)
+	}
+}
+
+func TestWelchOfTooShortSignalIsEmpty(t *testing.T) {
+	f, psd := Welch(noise(10), 1, Hann(16, Periodic), 8, NoDetrend)
+	check.Eq(t, f, nil)
+	check.Eq(t, psd, nil)
+}
+
+func TestWelchWithZeroWindowReturnsNil(t *testing.T) {
+	x := noise(64)
+	window := make([]float64, 16)
+	freqs, psd := Welch(x, 1, window, 8, NoDetrend)
+	check.Eq(t, freqs, nil)
+	check.Eq(t, psd, nil)
+	_, csd := CSD(x, x, 1, window, 8, NoDetrend)
+	check.Eq(t, csd, nil)
+	_, coherence := Coherence(x, x, 1, window, 8, NoDetrend)
+	check.Eq(t, coherence, nil)
+}
+
+func TestCSDOfSignalWithItselfIsPSD(t *testing.T) {
+	x := noise(1000)
+	window := Hann(100, Periodic)
+	f1, psd := Welch(x, 5, window, 50, LinearDetrend)
+	f2, csd := CSD(x, x, 5, window, 50, LinearDetrend)
+	check.Eq(t, f1, f2)
+	check.EqEps(t, csd, ToComplex(psd), 1e-6)
+}
+
+func TestCSDPhaseIsThatOfYRelativeToX(t *testing.T) {
+	n := 1024
+	x := sine(n, 1, 64, 1024)
+	y := make([]float64, n)
+	for i := range y {
+		// Cosine leads the sine by 90 degrees.
+		y[i] = x[(i+4)%n]
+	}
+	_, csd := CSD(x, y, 1024, Hann(256, Periodic), 128, NoDetrend)
+	phase := Phase(csd)
+	check.EqEps(t, phase[16], 3.14159/2, 1e-3)
+}
+
+func TestCoherence(t *testing.T) {
+	x := noise(20000)
+	y := Scale(x, -3)
+	_, c := Coherence(x, y, 1, Hann(128, Periodic), 64, ConstantDetrend)
+	for i := 1; i < len(c)-1; i++ {
+		check.EqEps(t, c[i], 1, 1e-3)
+	}
+
+	independent := Reverse(x)
+	_, c = Coherence(x, independent, 1, Hann(128, Periodic), 64, ConstantDetrend)
+	check.Eq(t, Average(c) < 0.05, true)
+}
+
+func TestCoherenceUsesShorterLength(t *testing.T) {
+	x := noise(300)
+	f, c := Coherence(x, x[:100], 1, Hann(64, Periodic), 32, NoDetrend)
+	check.Eq(t, len(f), 33)
+	check.Eq(t, len(c), 33)
+}
@@ -0,0 +1,107 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestWindowsOfNonPositiveLengthAreEmpty(t *testing.T) {
+	check.Eq(t, Hann(0, Symmetric), nil)
+	check.Eq(t, Kaiser(-1, 5, Periodic), nil)
+	check.Eq(t, DolphChebyshev(0, 60, Symmetric), nil)
+}
+
+func TestWindowsOfLengthOneAreOne(t *testing.T) {
+	check.Eq(t, Hann(1, Symmetric), []float64{1})
+	check.Eq(t, Blackman(1, Periodic), []float64{1})
+	check.Eq(t, DolphChebyshev(1, 60, Symmetric), []float64{1})
+}
+
+func TestHannWindow(t *testing.T) {
+	check.EqEps(t, Hann(5, Symmetric), []float64{0, 0.5, 1, 0.5, 0}, 1e-6)
+	check.EqEps(t, Hann(4, Periodic), []float64{0, 0.5, 1, 0.5}, 1e-6)
+}
+
+func TestHammingWindow(t *testing.T) {
+	check.EqEps(t, Hamming(3, Symmetric), []float64{0.08, 1, 0.08}, 1e-6)
+}
+
+func TestPeriodicWindowIsSymmetricWindowOfLengthPlusOneWithoutLastValue(t *testing.T) {
+	check.Eq(t, BlackmanHarris(8, Periodic), BlackmanHarris(9, Symmetric)[:8])
+	check.Eq(t, Kaiser(8, 6, Periodic), Kaiser(9, 6, Symmetric)[:8])
+}
+
+func TestWindowsAreSymmetric(t *testing.T) {
+	windows := [][]float64{
+		Hann(9, Symmetric),
+		Hamming(10, Symmetric),
+		Blackman(9, Symmetric),
+		BlackmanHarris(10, Symmetric),
+		FlatTop(9, Symmetric),
+		Kaiser(10, 8, Symmetric),
+		Tukey(9, 0.5, Symmetric),
+		Gaussian(10, 2, Symmetric),
+		DolphChebyshev(9, 80, Symmetric),
+		DolphChebyshev(10, 80, Symmetric),
+	}
+	for i, w := range windows {
+		check.EqEps(t, w, Reverse(w), 1e-6, "window ", i)
+	}
+}
+
+func TestKaiserWithZeroBetaIsRectangular(t *testing.T) {
+	check.Eq(t, Kaiser(4, 0, Symmetric), []float64{1, 1, 1, 1})
+}
+
+func TestTukeyBlendsBetweenRectangleAndHann(t *testing.T) {
+	check.Eq(t, Tukey(5, 0, Symmetric), []float64{1, 1, 1, 1, 1})
+	check.Eq(t, Tukey(5, 1, Symmetric), Hann(5, Symmetric))
+	check.EqEps(t, Tukey(9, 0.5, Symmetric), []float64{0, 0.5, 1, 1, 1, 1, 1, 0.5, 0}, 1e-6)
+}
+
+func TestGaussianWindow(t *testing.T) {
+	w := Gaussian(5, 1, Symmetric)
+	e := float64(math.Exp(-0.5))
+	e4 := float64(math.Exp(-2))
+	check.EqEps(t, w, []float64{e4, e, 1, e, e4}, 1e-6)
+	check.Eq(t, Gaussian(5, 0, Symmetric), nil)
+	check.Eq(t, Gaussian(5, -1, Symmetric), nil)
+}
+
+func TestDolphChebyshevSideLobesAreAtAttenuation(t *testing.T) {
+	for _, n := range []int{31, 32} {
+		w := DolphChebyshev(n, 60, Symmetric)
+		check.EqEps(t, MaxValue(w), 1, 1e-6)
+
+		spectrum := Magnitude(FFT(ToComplex(append(w, make([]float64, 4096-n)...))))
+		peak := spectrum[0]
+		i := 1
+		for spectrum[i] < spectrum[i-1] {
+			i++
+		}
+		sideLobe := 20 * math.Log10(float64(MaxValue(spectrum[i:2048])/peak))
+		check.EqEps(t, sideLobe, -60, 0.1, "n=", n)
+	}
+}
+
+func TestWindowCorrectionFactors(t *testing.T) {
+	rect := Repeat(1, 64)
+	check.Eq(t, CoherentGain(rect), 1)
+	check.Eq(t, ENBW(rect), 1)
+	check.EqEps(t, ScallopingLoss(rect), 3.92, 0.01)
+
+	hann := Hann(64, Periodic)
+	check.EqEps(t, CoherentGain(hann), 0.5, 1e-6)
+	check.EqEps(t, ENBW(hann), 1.5, 1e-5)
+	check.EqEps(t, ScallopingLoss(hann), 1.42, 0.01)
+
+	check.EqEps(t, ENBW(Hamming(1024, Periodic)), 1.36, 0.01)
+	check.EqEps(t, ENBW(BlackmanHarris(1024, Periodic)), 2.0, 0.01)
+	check.EqEps(t, ScallopingLoss(FlatTop(1024, Periodic)), 0, 0.02)
+
+	check.Eq(t, CoherentGain(nil), 0)
+	check.Eq(t, ENBW(nil), 0)
+	check.Eq(t, ScallopingLoss(nil), 0)
+}
@@ -0,0 +1,122 @@
+package dsp
+
+import (
+	"math/cmplx"
+	"sort"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+// sortRoots sorts the roots by their real and then imaginary parts, rounded to
+// a few digits.
+func sortRoots(r []complex128) []complex128 {
+	s := make([]complex128, len(r))
+	for i := range r {
+		s[i] = complex128(r[i])
+	}
+	sort.Slice(s, func(i, j int) bool {
+		if d := real(s[i]) - real(s[j]); d < -1e-4 || d > 1e-4 {
+			return d < 0
+		}
+		return imag(s[i]) < imag(s[j])
+	})
+	return s
+}
+
+func TestRoots(t *testing.T) {
+	// (x-1)(x+2)(x-3) = x³ - 2x² - 5x + 6
+	r := sortRoots(Roots([]float64{1, -2, -5, 6}))
+	check.EqEps(t, r, []complex128{-2, 1, 3}, 1e-5)
+
+	// x³ + x, with a leading zero coefficient
+	r = sortRoots(Roots([]float64{0, 2, 0, 2, 0}))
+	check.EqEps(t, r, []complex128{-1i, 0, 1i}, 1e-5)
+
+	check.Eq(t, len(Roots([]float64{0, 3})), 0)
+	check.Eq(t, len(Roots(nil)), 0)
+}
+
+func TestTFToZPK(t *testing.T) {
+	// H(z) = 2*(1 - 0.5z^-1) / (1 - 0.25z^-2)
+	f := TFToZPK([]float64{2, -1}, []float64{1, 0, -0.25})
+	check.EqEps(t, sortRoots(f.Zeros), []complex128{0, 0.5}, 1e-6)
+	check.EqEps(t, sortRoots(f.Poles), []complex128{-0.5, 0.5}, 1e-6)
+	check.EqEps(t, float64(f.Gain), 2, 1e-6)
+
+	// A delay z^-1 has a pole at the origin and no zeros.
+	f = TFToZPK([]float64{0, 1}, []float64{1})
+	check.Eq(t, len(f.Zeros), 0)
+	check.EqEps(t, sortRoots(f.Poles), []complex128{0}, 1e-6)
+	check.Eq(t, f.Gain, float64(1))
+
+	check.Eq(t, TFToZPK([]float64{1}, []float64{0, 1}), ZPK{})
+}
+
+func TestZPKToTF(t *testing.T) {
+	b, a := ZPKToTF(ZPK{
+		Zeros: []complex128{0.5},
+		Poles: []complex128{0.5i, -0.5i},
+		Gain:  2,
+	})
+	check.EqEps(t, b, []float64{0, 2, -1}, 1e-6)
+	check.EqEps(t, a, []float64{1, 0, 0.25}, 1e-6)
+
+	b2, a2 := ZPKToTF(TFToZPK(b, a))
+	check.EqEps(t, b2, b, 1e-5)
+	check.EqEps(t, a2, a, 1e-5)
+}
+
+func TestSOSConversionsRoundTrip(t *testing.T) {
+	sos := Elliptic(5, 1, 40, Lowpass, 10000, 1000)
+	freqs := FrequencyGrid(32, 10000)
+	want := SOSFreqResponse(sos, freqs, 10000)
+
+	b, a := SOSToTF(sos)
+	check.Eq(t, len(b), 7)
+	check.EqEps(t, FreqResponse(b, a, freqs, 10000), want, 1e-3)
+
+	f := SOSToZPK(sos)
+	check.Eq(t, len(f.Zeros), 6)
+	check.Eq(t, len(f.Poles), 6)
+	check.EqEps(t, SOSFreqResponse(ZPKToSOS(f), freqs, 10000), want, 1e-3)
+	check.EqEps(t, SOSFreqResponse(TFToSOS(b, a), freqs, 10000), want, 1e-3)
+}
+
+func TestZPKToSOSPairsPoles(t *testing.T) {
+	f := ZPK{
+		Zeros: []complex128{-1, -1, complex(0, 1), complex(0, -1)},
+		Poles: []complex128{0.5, complex(0.9, 0.3), complex(0.9, -0.3), 0.2},
+		Gain:  3,
+	}
+	sos := ZPKToSOS(f)
+	check.Eq(t, len(sos), 2)
+	// The complex poles are closer to the unit circle and go last, with the
+	// closest zeros, the ones at ±i.
+	check.EqEps(t, sos[1], Biquad{B0: 1, B1: 0, B2: 1, A1: -1.8, A2: 0.9}, 1e-6)
+	check.EqEps(t, sos[0], Biquad{B0: 3, B1: 6, B2: 3, A1: -0.7, A2: 0.1}, 1e-6)
+
+	// Odd numbers of roots are made up with roots at the origin.
+	sos = ZPKToSOS(ZPK{Zeros: []complex128{-1}, Poles: []complex128{0.5}, Gain: 1})
+	check.EqEps(t, sos, []Biquad{{B0: 1, B1: 1, A1: -0.5}}, 1e-6)
+}
+
+func TestStability(t *testing.T) {
+	check.Eq(t, SOSToZPK(Butterworth(6, Bandpass, 10000, 1000, 2000)).IsStable(), true)
+	check.Eq(t, SOSToZPK(Butterworth(6, Bandpass, 10000, 1000, 2000)).IsMinimumPhase(), true)
+
+	f := TFToZPK([]float64{1}, []float64{1, -2.5, 1})
+	check.Eq(t, f.IsStable(), false)
+	check.Eq(t, f.IsMinimumPhase(), false)
+	outside := f.UnstablePoles()
+	check.Eq(t, len(outside), 1)
+	check.EqEps(t, cmplx.Abs(complex128(outside[0])), 2, 1e-5)
+
+	// An integrator has a pole on the unit circle.
+	check.Eq(t, TFToZPK([]float64{1}, []float64{1, -1}).IsStable(), false)
+
+	// A zero outside the unit circle makes the filter non-minimum phase.
+	f = TFToZPK([]float64{1, -3}, []float64{1, -0.5})
+	check.Eq(t, f.IsStable(), true)
+	check.Eq(t, f.IsMinimumPhase(), false)
+}
@@ -2,6 +2,7 @@ package dsp
 
 import (
 	"math"
+	"sort"
 	"testing"
 
 	"github.com/gonutz/check"
@@ -78,6 +79,24 @@ func TestMedianFilter(t *testing.T) {
 	check.Eq(t, MedianFilter([]FLOAT{1, 3, 2}, 1), []FLOAT{1, 3, 2})
 }
 
+func TestMedianFilterMatchesSortingEveryWindow(t *testing.T) {
+	// Quantize the noise so that the windows contain duplicates.
+	a := noise(300)
+	for i := range a {
+		a[i] = FLOAT(math.Floor(float64(a[i]) * 8))
+	}
+	for _, width := range []int{2, 3, 4, 5, 16, 31, 100, 299, 300} {
+		want := make([]FLOAT, len(a)-width+1)
+		buf := make([]FLOAT, width)
+		for i := range want {
+			copy(buf, a[i:])
+			sort.Sort(floats(buf))
+			want[i] = buf[width/2]
+		}
+		check.Eq(t, MedianFilter(a, width), want, width)
+	}
+}
+
 func TestMedianFilterLeavesAtLeastOneElement(t *testing.T) {
 	check.Eq(t, MedianFilter([]FLOAT{1, 2, 3}, 3), []FLOAT{2})
 	check.Eq(t, MedianFilter([]FLOAT{1, 2, 3}, 4), []FLOAT{2})
@@ -269,3 +288,19 @@ func TestResample(t *testing.T) {
 	check.Eq(t, Resample([]FLOAT{100, 200}, 3), []FLOAT{100, 150, 200})
 	check.Eq(t, Resample([]FLOAT{100, 120, 140, 160, 180, 200}, 3), []FLOAT{100, 150, 200})
 }
+
+func TestMagnitudePhaseAndPower(t *testing.T) {
+	c := []COMPLEX{3 + 4i, -2, 1i, 0}
+	check.Eq(t, Magnitude(c), []FLOAT{5, 2, 1, 0})
+	check.Eq(t, Phase(c), []FLOAT{FLOAT(math.Atan2(4, 3)), math.Pi, math.Pi / 2, 0})
+	check.Eq(t, Power(c), []FLOAT{25, 4, 1, 0})
+	check.Eq(t, Magnitude(nil), nil)
+}
+
+func TestUnwrapRemovesPhaseJumps(t *testing.T) {
+	check.Eq(t, Unwrap(nil), nil)
+	check.Eq(t, Unwrap([]FLOAT{1}), []FLOAT{1})
+	check.Eq(t, Unwrap([]FLOAT{0, 1, 2, 3}), []FLOAT{0, 1, 2, 3})
+	check.EqEps(t, Unwrap([]FLOAT{2.5, -2.5, 1.5}), []FLOAT{2.5, 2*math.Pi - 2.5, 1.5}, 1e-5)
+	check.EqEps(t, Unwrap([]FLOAT{-3, 3}), []FLOAT{-3, 3 - 2*math.Pi}, 1e-5)
+}
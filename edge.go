@@ -0,0 +1,143 @@
+package dsp
+
+import "sort"
+
+// EdgeMode selects how filters with centered windows handle the parts of the
+// window that reach beyond the ends of the signal. The examples show how the
+// signal 1, 2, 3 is continued to the left.
+type EdgeMode int
+
+const (
+	// EdgeShrink shrinks the window at the ends so that it only covers
+	// existing samples.
+	EdgeShrink EdgeMode = iota
+
+	// EdgeReflect mirrors the signal at its end samples: ... 3, 2, | 1, 2, 3
+	EdgeReflect
+
+	// EdgeReplicate repeats the end samples: ... 1, 1, | 1, 2, 3
+	EdgeReplicate
+
+	// EdgeConstant continues the signal with a constant value c:
+	// ... c, c, | 1, 2, 3
+	EdgeConstant
+
+	// EdgeWrap continues the signal periodically: ... 2, 3, | 1, 2, 3
+	EdgeWrap
+)
+
+// AverageFilterSame is like AverageFilter but returns len(a) values, each the
+// average over the window of width samples centered on the respective sample
+// of a. For even widths, the window has one more sample before the center than
+// after it. edge selects how the window is filled beyond the ends of a,
+// constant is the value used for EdgeConstant.
+// If the width is 1 or smaller, a copy of a is returned.
+func AverageFilterSame(a []FLOAT, width int, edge EdgeMode, constant FLOAT) []FLOAT {
+	if width <= 1 || len(a) == 0 {
+		return Copy(a)
+	}
+	if edge != EdgeShrink {
+		return AverageFilter(extendEdges(a, width, edge, constant), width)
+	}
+
+	// Use prefix sums to average over windows of varying sizes.
+	sums := make([]float64, len(a)+1)
+	for i, v := range a {
+		sums[i+1] = sums[i] + float64(v)
+	}
+	b := make([]FLOAT, len(a))
+	for i := range b {
+		start, end := shrunkWindow(i, len(a), width)
+		b[i] = FLOAT((sums[end] - sums[start]) / float64(end-start))
+	}
+	return b
+}
+
+// MedianFilterSame is like MedianFilter but returns len(a) values, each the
+// median of the window of width samples centered on the respective sample of
+// a. See AverageFilterSame for the window and the other parameters. For even
+// window sizes, the upper of the two middle values is used, like MedianFilter
+// does.
+// If the width is 1 or smaller, a copy of a is returned.
+func MedianFilterSame(a []FLOAT, width int, edge EdgeMode, constant FLOAT) []FLOAT {
+	if width <= 1 || len(a) == 0 {
+		return Copy(a)
+	}
+	if edge != EdgeShrink {
+		return MedianFilter(extendEdges(a, width, edge, constant), width)
+	}
+
+	b := make([]FLOAT, len(a))
+	if len(a) >= width {
+		copy(b[width/2:], MedianFilter(a, width))
+	}
+	buf := make([]FLOAT, width)
+	for i := range b {
+		start, end := shrunkWindow(i, len(a), width)
+		if end-start == width {
+			continue
+		}
+		w := buf[:end-start]
+		copy(w, a[start:end])
+		sort.Sort(floats(w))
+		b[i] = w[len(w)/2]
+	}
+	return b
+}
+
+// shrunkWindow returns the range of the window of the given width centered on
+// sample i, limited to the n samples of the signal.
+func shrunkWindow(i, n, width int) (start, end int) {
+	start = i - width/2
+	end = start + width
+	if start < 0 {
+		start = 0
+	}
+	if end > n {
+		end = n
+	}
+	return
+}
+
+// extendEdges returns a with width/2 samples added before and (width-1)/2
+// samples added after it, as selected by edge. a must not be empty.
+func extendEdges(a []FLOAT, width int, edge EdgeMode, constant FLOAT) []FLOAT {
+	before := width / 2
+	n := len(a)
+	ext := make([]FLOAT, n+width-1)
+	for i := range ext {
+		ext[i] = edgeSample(a, i-before, edge, constant)
+	}
+	return ext
+}
+
+// edgeSample returns a[i] for the index i, which may lie outside of a, as
+// selected by edge.
+func edgeSample(a []FLOAT, i int, edge EdgeMode, constant FLOAT) FLOAT {
+	n := len(a)
+	if i >= 0 && i < n {
+		return a[i]
+	}
+	switch edge {
+	case EdgeConstant:
+		return constant
+	case EdgeWrap:
+		return a[(i%n+n)%n]
+	case EdgeReflect:
+		if n == 1 {
+			return a[0]
+		}
+		// Mirroring at both ends makes the signal periodic.
+		period := 2 * (n - 1)
+		i = (i%period + period) % period
+		if i >= n {
+			i = period - i
+		}
+		return a[i]
+	default:
+		if i < 0 {
+			return a[0]
+		}
+		return a[n-1]
+	}
+}
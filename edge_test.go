@@ -0,0 +1,66 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestEdgeModes(t *testing.T) {
+	a := []FLOAT{1, 2, 3}
+	ext := func(edge EdgeMode) []FLOAT {
+		e := make([]FLOAT, 11)
+		for i := range e {
+			e[i] = edgeSample(a, i-4, edge, 9)
+		}
+		return e
+	}
+	check.Eq(t, ext(EdgeReflect), []FLOAT{1, 2, 3, 2, 1, 2, 3, 2, 1, 2, 3})
+	check.Eq(t, ext(EdgeReplicate), []FLOAT{1, 1, 1, 1, 1, 2, 3, 3, 3, 3, 3})
+	check.Eq(t, ext(EdgeConstant), []FLOAT{9, 9, 9, 9, 1, 2, 3, 9, 9, 9, 9})
+	check.Eq(t, ext(EdgeWrap), []FLOAT{3, 1, 2, 3, 1, 2, 3, 1, 2, 3, 1})
+	check.Eq(t, edgeSample([]FLOAT{5}, -3, EdgeReflect, 0), FLOAT(5))
+}
+
+func TestAverageFilterSame(t *testing.T) {
+	a := []FLOAT{3, 6, 9, 12, 15}
+	check.Eq(t, AverageFilterSame(a, 3, EdgeShrink, 0), []FLOAT{4.5, 6, 9, 12, 13.5})
+	check.Eq(t, AverageFilterSame(a, 3, EdgeReflect, 0), []FLOAT{5, 6, 9, 12, 13})
+	check.Eq(t, AverageFilterSame(a, 3, EdgeReplicate, 0), []FLOAT{4, 6, 9, 12, 14})
+	check.Eq(t, AverageFilterSame(a, 3, EdgeConstant, 3), []FLOAT{4, 6, 9, 12, 10})
+	check.Eq(t, AverageFilterSame(a, 3, EdgeWrap, 0), []FLOAT{8, 6, 9, 12, 10})
+
+	// Even widths have one more sample before the center.
+	check.Eq(t, AverageFilterSame(a, 2, EdgeShrink, 0), []FLOAT{3, 4.5, 7.5, 10.5, 13.5})
+	check.Eq(t, AverageFilterSame(a, 4, EdgeShrink, 0), []FLOAT{4.5, 6, 7.5, 10.5, 12})
+
+	// The center part matches AverageFilter.
+	x := realTestSignal(50)
+	check.EqEps(t, AverageFilterSame(x, 7, EdgeReflect, 0)[3:47], AverageFilter(x, 7), 1e-5)
+
+	// Windows wider than the signal.
+	check.Eq(t, AverageFilterSame([]FLOAT{1, 2}, 7, EdgeShrink, 0), []FLOAT{1.5, 1.5})
+	check.EqEps(t, AverageFilterSame([]FLOAT{1, 2}, 5, EdgeWrap, 0), []FLOAT{1.4, 1.6}, 1e-6)
+}
+
+func TestMedianFilterSame(t *testing.T) {
+	a := []FLOAT{5, 1, 9, 3, 7}
+	check.Eq(t, MedianFilterSame(a, 3, EdgeShrink, 0), []FLOAT{5, 5, 3, 7, 7})
+	check.Eq(t, MedianFilterSame(a, 3, EdgeReflect, 0), []FLOAT{1, 5, 3, 7, 3})
+	check.Eq(t, MedianFilterSame(a, 3, EdgeReplicate, 0), []FLOAT{5, 5, 3, 7, 7})
+	check.Eq(t, MedianFilterSame(a, 3, EdgeConstant, 0), []FLOAT{1, 5, 3, 7, 3})
+	check.Eq(t, MedianFilterSame(a, 3, EdgeWrap, 0), []FLOAT{5, 5, 3, 7, 5})
+	check.Eq(t, MedianFilterSame(a, 5, EdgeShrink, 0), []FLOAT{5, 5, 5, 7, 7})
+
+	x := realTestSignal(50)
+	check.Eq(t, MedianFilterSame(x, 6, EdgeWrap, 0)[3:48], MedianFilter(x, 6))
+	check.Eq(t, MedianFilterSame([]FLOAT{4, 2}, 9, EdgeShrink, 0), []FLOAT{4, 4})
+}
+
+func TestSameFiltersEdgeCases(t *testing.T) {
+	a := []FLOAT{1, 2, 3}
+	check.Eq(t, AverageFilterSame(a, 1, EdgeReflect, 0), a)
+	check.Eq(t, MedianFilterSame(a, 0, EdgeReflect, 0), a)
+	check.Eq(t, AverageFilterSame(nil, 3, EdgeReflect, 0), []FLOAT{})
+	check.Eq(t, MedianFilterSame(nil, 3, EdgeShrink, 0), []FLOAT{})
+}
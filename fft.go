@@ -0,0 +1,425 @@
+package dsp
+
+import "math"
+
+// FFTPlan holds the precomputed twiddle factors and scratch memory for Fourier
+// transforms of one fixed length. Creating a plan once and reusing it for every
+// block of that length avoids recomputing these tables on each call.
+// A plan is not safe for concurrent use, create one plan per goroutine instead.
+type FFTPlan struct {
+	n       int
+	twiddle []COMPLEX
+	scratch []COMPLEX
+
+	// Lengths that are not a power of two are transformed with Bluestein's
+	// algorithm, which expresses the DFT as a convolution of power of two
+	// length. chirp holds exp(-i*pi*k*k/n), kernel is the transformed
+	// conjugate chirp and conv is the plan for the convolution length.
+	chirp  []COMPLEX
+	kernel []COMPLEX
+	conv   *FFTPlan
+	buf    []COMPLEX
+}
+
+// NewFFTPlan creates a plan for transforms of length n. Powers of two are
+// transformed with radix-4 steps (and a single radix-2 step if needed), all
+// other lengths use Bluestein's algorithm.
+// If n <= 0, the plan can only transform empty slices.
+func NewFFTPlan(n int) *FFTPlan {
+	if n <= 0 {
+		return &FFTPlan{}
+	}
+
+	p := &FFTPlan{n: n}
+
+	if isPowerOfTwo(n) {
+		p.twiddle = make([]COMPLEX, n)
+		for k := range p.twiddle {
+			p.twiddle[k] = expi(-2 * math.Pi * float64(k) / float64(n))
+		}
+		p.scratch = make([]COMPLEX, n)
+		return p
+	}
+
+	m := nextPowerOfTwo(2*n - 1)
+	p.conv = NewFFTPlan(m)
+	p.chirp = make([]COMPLEX, n)
+	for k := range p.chirp {
+		// k*k gets large quickly, reducing it modulo 2n keeps the angle
+		// precise since exp(-i*pi*k*k/n) has period 2n in k*k.
+		kk := (int64(k) * int64(k)) % int64(2*n)
+		p.chirp[k] = expi(-math.Pi * float64(kk) / float64(n))
+	}
+	p.kernel = make([]COMPLEX, m)
+	p.kernel[0] = conj(p.chirp[0])
+	for k := 1; k < n; k++ {
+		p.kernel[k] = conj(p.chirp[k])
+		p.kernel[m-k] = p.kernel[k]
+	}
+	p.conv.Forward(p.kernel, p.kernel)
+	p.buf = make([]COMPLEX, m)
+	return p
+}
+
+// Len returns the transform length that p was created for.
+func (p *FFTPlan) Len() int {
+	return p.n
+}
+
+// Forward computes the discrete Fourier transform of src and writes it to dst,
+// i.e. dst[k] is the sum over all j of src[j] * exp(-2*pi*i*j*k/n).
+// The result is not normalized.
+// Both dst and src must have length Len(). They may be the same slice in which
+// case the transform is done in place.
+func (p *FFTPlan) Forward(dst, src []COMPLEX) {
+	p.checkLen(dst, src)
+	if p.n == 0 {
+		return
+	}
+	if p.conv != nil {
+		p.bluestein(dst, src)
+		return
+	}
+	copy(dst, src)
+	p.stockham(dst)
+}
+
+// Inverse computes the inverse discrete Fourier transform of src and writes it
+// to dst, i.e. dst[j] is the sum over all k of src[k] * exp(2*pi*i*j*k/n),
+// divided by n. This makes Inverse undo Forward.
+// Both dst and src must have length Len(). They may be the same slice in which
+// case the transform is done in place.
+func (p *FFTPlan) Inverse(dst, src []COMPLEX) {
+	p.checkLen(dst, src)
+	// The inverse transform is the conjugate of the forward transform of the
+	// conjugated input.
+	for i := range src {
+		dst[i] = conj(src[i])
+	}
+	p.Forward(dst, dst)
+	scale := 1 / FLOAT(p.n)
+	for i := range dst {
+		dst[i] = complex(real(dst[i])*scale, -imag(dst[i])*scale)
+	}
+}
+
+func (p *FFTPlan) checkLen(dst, src []COMPLEX) {
+	if len(dst) != p.n || len(src) != p.n {
+		panic("dsp: FFTPlan used with wrong slice length")
+	}
+}
+
+// stockham transforms x in place with the self-sorting Stockham algorithm,
+// which needs no bit reversal but ping-pongs between x and the scratch buffer.
+func (p *FFTPlan) stockham(x []COMPLEX) {
+	src, dst := x, p.scratch
+	// n is the length of the sub-transforms in the current stage, s is the
+	// stride between their elements. n*s is always the full length.
+	n, s := p.n, 1
+	for n > 1 {
+		if n%4 == 0 {
+			q := n / 4
+			for j := 0; j < q; j++ {
+				w1 := p.twiddle[j*s]
+				w2 := p.twiddle[2*j*s]
+				w3 := p.twiddle[3*j*s]
+				for k := 0; k < s; k++ {
+					a := src[k+s*j]
+					b := src[k+s*(j+q)]
+					c := src[k+s*(j+2*q)]
+					d := src[k+s*(j+3*q)]
+					apc := a + c
+					amc := a - c
+					bpd := b + d
+					jbmd := mulI(b - d)
+					dst[k+s*(4*j)] = apc + bpd
+					dst[k+s*(4*j+1)] = w1 * (amc - jbmd)
+					dst[k+s*(4*j+2)] = w2 * (apc - bpd)
+					dst[k+s*(4*j+3)] = w3 * (amc + jbmd)
+				}
+			}
+			n /= 4
+			s *= 4
+		} else {
+			q := n / 2
+			for j := 0; j < q; j++ {
+				w := p.twiddle[j*s]
+				for k := 0; k < s; k++ {
+					a := src[k+s*j]
+					b := src[k+s*(j+q)]
+					dst[k+s*(2*j)] = a + b
+					dst[k+s*(2*j+1)] = w * (a - b)
+				}
+			}
+			n /= 2
+			s *= 2
+		}
+		src, dst = dst, src
+	}
+	if &src[0] != &x[0] {
+		copy(x, src)
+	}
+}
+
+func (p *FFTPlan) bluestein(dst, src []COMPLEX) {
+	a := p.buf
+	for k := range src {
+		a[k] = src[k] * p.chirp[k]
+	}
+	for k := len(src); k < len(a); k++ {
+		a[k] = 0
+	}
+	p.conv.Forward(a, a)
+	for k := range a {
+		a[k] *= p.kernel[k]
+	}
+	p.conv.Inverse(a, a)
+	for k := range dst {
+		dst[k] = a[k] * p.chirp[k]
+	}
+}
+
+// FFT returns the discrete Fourier transform of x. It works for any length of
+// x. See FFTPlan.Forward for the exact definition.
+// If you transform many slices of the same length, create an FFTPlan once and
+// use it instead.
+func FFT(x []COMPLEX) []COMPLEX {
+	y := make([]COMPLEX, len(x))
+	NewFFTPlan(len(x)).Forward(y, x)
+	return y
+}
+
+// IFFT returns the inverse discrete Fourier transform of x, so that
+// IFFT(FFT(x)) is x again. It works for any length of x. See
+// FFTPlan.Inverse for the exact definition.
+// If you transform many slices of the same length, create an FFTPlan once and
+// use it instead.
+func IFFT(x []COMPLEX) []COMPLEX {
+	y := make([]COMPLEX, len(x))
+	NewFFTPlan(len(x)).Inverse(y, x)
+	return y
+}
+
+// RealFFTPlan is the equivalent of FFTPlan for real valued input. Since the
+// spectrum of a real signal is conjugate symmetric, only the n/2+1 bins from
+// DC up to the Nyquist frequency are computed. For even lengths the n real
+// values are transformed as n/2 complex values which takes about half the work
+// and memory of a complex transform.
+// A plan is not safe for concurrent use, create one plan per goroutine instead.
+type RealFFTPlan struct {
+	n int
+
+	// For even n, half transforms the even samples as real parts and the odd
+	// samples as imaginary parts. twiddle holds exp(-2*pi*i*k/n) to separate
+	// the two afterwards.
+	half    *FFTPlan
+	twiddle []COMPLEX
+
+	// For odd n, full transforms the complete signal.
+	full *FFTPlan
+
+	buf []COMPLEX
+}
+
+// NewRealFFTPlan creates a plan for real transforms of length n.
+// If n <= 0, the plan can only transform empty slices.
+func NewRealFFTPlan(n int) *RealFFTPlan {
+	if n <= 0 {
+		return &RealFFTPlan{}
+	}
+
+	p := &RealFFTPlan{n: n}
+	if n%2 == 0 {
+		m := n / 2
+		p.half = NewFFTPlan(m)
+		p.twiddle = make([]COMPLEX, m+1)
+		for k := range p.twiddle {
+			p.twiddle[k] = expi(-2 * math.Pi * float64(k) / float64(n))
+		}
+		p.buf = make([]COMPLEX, m)
+	} else {
+		p.full = NewFFTPlan(n)
+		p.buf = make([]COMPLEX, n)
+	}
+	return p
+}
+
+// Len returns the length of the real signals that p transforms.
+func (p *RealFFTPlan) Len() int {
+	return p.n
+}
+
+// Bins returns the number of frequency bins, n/2+1, of the spectrum. For an
+// empty plan this is 0.
+func (p *RealFFTPlan) Bins() int {
+	if p.n == 0 {
+		return 0
+	}
+	return p.n/2 + 1
+}
+
+// Forward computes the one-sided discrete Fourier transform of src and writes
+// it to dst. dst[k] is the same as FFTPlan.Forward would compute for bin k,
+// for k from 0 (DC) to n/2 (Nyquist for even n). The result is not
+// normalized.
+// src must have length Len() and dst must have length Bins().
+func (p *RealFFTPlan) Forward(dst []COMPLEX, src []FLOAT) {
+	if len(src) != p.n || len(dst) != p.Bins() {
+		panic("dsp: RealFFTPlan used with wrong slice length")
+	}
+	if p.n == 0 {
+		return
+	}
+
+	if p.full != nil {
+		for i := range src {
+			p.buf[i] = complex(src[i], 0)
+		}
+		p.full.Forward(p.buf, p.buf)
+		copy(dst, p.buf)
+		return
+	}
+
+	m := p.n / 2
+	z := p.buf
+	for k := range z {
+		z[k] = complex(src[2*k], src[2*k+1])
+	}
+	p.half.Forward(z, z)
+	for k := 0; k <= m; k++ {
+		zk := z[k%m]
+		zc := conj(z[(m-k)%m])
+		even := (zk + zc) / 2
+		odd := -mulI(zk-zc) / 2
+		dst[k] = even + p.twiddle[k]*odd
+	}
+}
+
+// Inverse computes the real signal from its one-sided spectrum src and writes
+// it to dst. It undoes Forward. The imaginary parts of the DC bin and, for even
+// lengths, the Nyquist bin are ignored since they are zero for real signals.
+// src must have length Bins() and dst must have length Len().
+func (p *RealFFTPlan) Inverse(dst []FLOAT, src []COMPLEX) {
+	if len(dst) != p.n || len(src) != p.Bins() {
+		panic("dsp: RealFFTPlan used with wrong slice length")
+	}
+	if p.n == 0 {
+		return
+	}
+
+	if p.full != nil {
+		n := p.n
+		p.buf[0] = complex(real(src[0]), 0)
+		for k := 1; k < len(src); k++ {
+			p.buf[k] = src[k]
+			p.buf[n-k] = conj(src[k])
+		}
+		p.full.Inverse(p.buf, p.buf)
+		for i := range dst {
+			dst[i] = real(p.buf[i])
+		}
+		return
+	}
+
+	m := p.n / 2
+	z := p.buf
+	first := complex(real(src[0]), 0)
+	last := complex(real(src[m]), 0)
+	for k := 0; k < m; k++ {
+		xk, xc := src[k], conj(src[m-k])
+		if k == 0 {
+			xk, xc = first, last
+		}
+		even := (xk + xc) / 2
+		odd := (xk - xc) / 2 * conj(p.twiddle[k])
+		z[k] = even + mulI(odd)
+	}
+	p.half.Inverse(z, z)
+	for k := range z {
+		dst[2*k] = real(z[k])
+		dst[2*k+1] = imag(z[k])
+	}
+}
+
+// RFFT returns the one-sided discrete Fourier transform of the real signal x.
+// The result has len(x)/2+1 bins, from DC up to the Nyquist frequency. The
+// remaining bins of the full spectrum are the complex conjugates of these.
+// For empty x an empty result is returned.
+// If you transform many slices of the same length, create a RealFFTPlan once
+// and use it instead.
+func RFFT(x []FLOAT) []COMPLEX {
+	p := NewRealFFTPlan(len(x))
+	y := make([]COMPLEX, p.Bins())
+	p.Forward(y, x)
+	return y
+}
+
+// IRFFT returns the real signal of length n with the one-sided spectrum x, so
+// that IRFFT(RFFT(a), len(a)) is a again. n must be given because both n = 2m
+// and n = 2m+1 have m+1 bins.
+// If x has fewer than n/2+1 values the missing bins are treated as zero, extra
+// values are ignored. If n <= 0, an empty result is returned.
+func IRFFT(x []COMPLEX, n int) []FLOAT {
+	if n <= 0 {
+		return nil
+	}
+	p := NewRealFFTPlan(n)
+	bins := make([]COMPLEX, p.Bins())
+	copy(bins, x)
+	y := make([]FLOAT, n)
+	p.Inverse(y, bins)
+	return y
+}
+
+// ToComplex returns a new array with the values of a as real parts and zero
+// imaginary parts.
+func ToComplex(a []FLOAT) []COMPLEX {
+	c := make([]COMPLEX, len(a))
+	for i := range c {
+		c[i] = complex(a[i], 0)
+	}
+	return c
+}
+
+// RealParts returns a new array of the real parts of the values in c.
+func RealParts(c []COMPLEX) []FLOAT {
+	a := make([]FLOAT, len(c))
+	for i := range a {
+		a[i] = real(c[i])
+	}
+	return a
+}
+
+// ImagParts returns a new array of the imaginary parts of the values in c.
+func ImagParts(c []COMPLEX) []FLOAT {
+	a := make([]FLOAT, len(c))
+	for i := range a {
+		a[i] = imag(c[i])
+	}
+	return a
+}
+
+func expi(theta float64) COMPLEX {
+	return COMPLEX(complex(math.Cos(theta), math.Sin(theta)))
+}
+
+func conj(c COMPLEX) COMPLEX {
+	return complex(real(c), -imag(c))
+}
+
+// mulI returns c multiplied by the imaginary unit.
+func mulI(c COMPLEX) COMPLEX {
+	return complex(-imag(c), real(c))
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
@@ -0,0 +1,132 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func naiveDFT(x []COMPLEX) []COMPLEX {
+	y := make([]COMPLEX, len(x))
+	for k := range y {
+		var sum complex128
+		for j := range x {
+			theta := -2 * math.Pi * float64(j*k) / float64(len(x))
+			sum += complex128(x[j]) * complex(math.Cos(theta), math.Sin(theta))
+		}
+		y[k] = COMPLEX(sum)
+	}
+	return y
+}
+
+func testSignal(n int) []COMPLEX {
+	x := make([]COMPLEX, n)
+	for i := range x {
+		x[i] = complex(FLOAT(math.Sin(float64(i)*0.7)+0.3), FLOAT(math.Cos(float64(i*i)*0.1)))
+	}
+	return x
+}
+
+func TestFFTMatchesDirectDFT(t *testing.T) {
+	for n := 0; n <= 70; n++ {
+		x := testSignal(n)
+		check.EqEps(t, FFT(x), naiveDFT(x), 1e-3, "n=", n)
+	}
+	for _, n := range []int{128, 256, 512, 100, 243, 1000} {
+		x := testSignal(n)
+		check.EqEps(t, FFT(x), naiveDFT(x), 1e-2, "n=", n)
+	}
+}
+
+func TestIFFTUndoesFFT(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 4, 5, 8, 12, 16, 17, 64, 97, 1024} {
+		x := testSignal(n)
+		check.EqEps(t, IFFT(FFT(x)), x, 1e-4, "n=", n)
+	}
+}
+
+func TestFFTOfImpulseIsFlat(t *testing.T) {
+	x := make([]COMPLEX, 6)
+	x[0] = 1
+	check.Eq(t, FFT(x), []COMPLEX{1, 1, 1, 1, 1, 1})
+}
+
+func TestFFTPlanCanBeReusedAndTransformInPlace(t *testing.T) {
+	for _, n := range []int{16, 20} {
+		p := NewFFTPlan(n)
+		check.Eq(t, p.Len(), n)
+		for run := 0; run < 3; run++ {
+			x := testSignal(n)
+			want := naiveDFT(x)
+			p.Forward(x, x)
+			check.EqEps(t, x, want, 1e-3)
+			p.Inverse(x, x)
+			check.EqEps(t, x, testSignal(n), 1e-4)
+		}
+	}
+}
+
+func TestFFTPlanDoesNotModifySource(t *testing.T) {
+	x := testSignal(12)
+	y := make([]COMPLEX, 12)
+	NewFFTPlan(12).Forward(y, x)
+	check.Eq(t, x, testSignal(12))
+}
+
+func TestFFTPlanPanicsOnWrongLength(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+	NewFFTPlan(4).Forward(make([]COMPLEX, 4), make([]COMPLEX, 3))
+}
+
+func TestComplexConversions(t *testing.T) {
+	c := ToComplex([]FLOAT{1, 2})
+	check.Eq(t, c, []COMPLEX{1, 2})
+	c[1] = complex(3, 4)
+	check.Eq(t, RealParts(c), []FLOAT{1, 3})
+	check.Eq(t, ImagParts(c), []FLOAT{0, 4})
+	check.Eq(t, ToComplex(nil), nil)
+}
+
+func realTestSignal(n int) []FLOAT {
+	return RealParts(testSignal(n))
+}
+
+func TestRFFTReturnsFirstHalfOfComplexFFT(t *testing.T) {
+	for n := 1; n <= 40; n++ {
+		x := realTestSignal(n)
+		full := naiveDFT(ToComplex(x))
+		check.EqEps(t, RFFT(x), full[:n/2+1], 1e-3, "n=", n)
+	}
+	check.Eq(t, RFFT(nil), nil)
+}
+
+func TestIRFFTUndoesRFFT(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 4, 7, 10, 16, 33, 100, 256} {
+		x := realTestSignal(n)
+		check.EqEps(t, IRFFT(RFFT(x), n), x, 1e-4, "n=", n)
+	}
+	check.Eq(t, IRFFT(nil, 0), nil)
+}
+
+func TestIRFFTZeroPadsMissingBins(t *testing.T) {
+	check.EqEps(t, IRFFT([]COMPLEX{4}, 4), []FLOAT{1, 1, 1, 1}, 1e-6)
+}
+
+func TestRealFFTPlanCanBeReused(t *testing.T) {
+	p := NewRealFFTPlan(12)
+	check.Eq(t, p.Len(), 12)
+	check.Eq(t, p.Bins(), 7)
+	spectrum := make([]COMPLEX, p.Bins())
+	signal := make([]FLOAT, p.Len())
+	for run := 0; run < 3; run++ {
+		x := realTestSignal(12)
+		p.Forward(spectrum, x)
+		p.Inverse(signal, spectrum)
+		check.EqEps(t, signal, x, 1e-5)
+	}
+}
@@ -0,0 +1,84 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestPadSignal(t *testing.T) {
+	x := []FLOAT{1, 2, 4}
+	check.Eq(t, padSignal(x, PadOdd, 2), []FLOAT{-2, 0, 1, 2, 4, 6, 7})
+	check.Eq(t, padSignal(x, PadEven, 2), []FLOAT{4, 2, 1, 2, 4, 2, 1})
+	check.Eq(t, padSignal(x, PadConstant, 2), []FLOAT{1, 1, 1, 2, 4, 4, 4})
+	check.Eq(t, padSignal(x, PadOdd, 0), x)
+}
+
+func TestIIRFiltFiltHasZeroPhase(t *testing.T) {
+	sos := Butterworth(4, Lowpass, 10000, 1000)
+	x := sine(1000, 1, 200, 10000)
+	y := IIRFiltFilt(x, sos, PadOdd, -1)
+	check.Eq(t, len(y), len(x))
+	gain := FLOAT(math.Pow(cmplx.Abs(sosResponse(sos, 200, 10000)), 2))
+	check.EqEps(t, y[50:950], Scale(x, gain)[50:950], 1e-4)
+	check.EqEps(t, y, Scale(x, gain), 0.01)
+}
+
+func TestFIRFiltFiltHasZeroPhase(t *testing.T) {
+	// An asymmetric filter has a non-linear phase, which is canceled.
+	taps := []FLOAT{0.5, 0.3, 0.2}
+	x := sine(1000, 1, 200, 10000)
+	y := FIRFiltFilt(x, taps, PadOdd, -1)
+	check.Eq(t, len(y), len(x))
+	gain := FLOAT(math.Pow(gainAt(taps, 200, 10000), 2))
+	check.EqEps(t, y[50:950], Scale(x, gain)[50:950], 1e-4)
+	check.EqEps(t, y, Scale(x, gain), 0.01)
+}
+
+func TestFiltFiltMatchesForwardBackwardFiltering(t *testing.T) {
+	taps := []FLOAT{0.5, 0.3, 0.2}
+	x := realTestSignal(100)
+	// Without padding and with constant signal ends, the steady state is the
+	// same as extending the signal with a constant.
+	want := Reverse(FIRFilter(Reverse(FIRFilter(x, taps, ConvolveFull)[:100]), taps, ConvolveFull)[:100])
+	y := FIRFiltFilt(x, taps, PadNone, 0)
+	check.EqEps(t, y[10:90], want[10:90], 1e-5)
+
+	sections := []Biquad{{B0: 0.5, B1: 0.3, B2: 0.2}}
+	check.EqEps(t, IIRFiltFilt(x, sections, PadOdd, 9), FIRFiltFilt(x, taps, PadOdd, 9), 1e-5)
+	check.EqEps(t, IIRFiltFilt(x, sections, PadEven, 9), FIRFiltFilt(x, taps, PadEven, 9), 1e-5)
+	check.EqEps(t, IIRFiltFilt(x, sections, PadConstant, 9), FIRFiltFilt(x, taps, PadConstant, 9), 1e-5)
+}
+
+func TestFiltFiltDoesNotRingAtTheEnds(t *testing.T) {
+	x := Repeat(3, 50)
+	sos := Butterworth(4, Lowpass, 10000, 1000)
+	check.EqEps(t, IIRFiltFilt(x, sos, PadOdd, -1), x, 1e-4)
+	check.EqEps(t, IIRFiltFilt(x, sos, PadNone, 0), x, 1e-4)
+
+	// A straight line is continued by odd padding. IIR filters need enough
+	// padding for their transients to decay.
+	ramp := Range(0, 299)
+	check.EqEps(t, IIRFiltFilt(ramp, sos, PadOdd, 100), ramp, 1e-3)
+	taps := FIRLowpass(1000, 10000, Hamming(21, Symmetric))
+	check.EqEps(t, FIRFiltFilt(ramp, taps, PadOdd, -1), ramp, 1e-3)
+}
+
+func TestIIRFiltFiltDefaultPadding(t *testing.T) {
+	// A 3rd order Butterworth filter has a first order section, the default
+	// padding is 3*(3+1) samples.
+	sos := Butterworth(3, Lowpass, 10000, 1000)
+	x := realTestSignal(60)
+	check.Eq(t, IIRFiltFilt(x, sos, PadEven, -1), IIRFiltFilt(x, sos, PadEven, 12))
+}
+
+func TestFiltFiltEdgeCases(t *testing.T) {
+	sos := Butterworth(2, Lowpass, 10000, 1000)
+	check.Eq(t, IIRFiltFilt(nil, sos, PadOdd, -1), []FLOAT{})
+	check.Eq(t, IIRFiltFilt([]FLOAT{1, 2}, nil, PadOdd, -1), []FLOAT{1, 2})
+	check.Eq(t, FIRFiltFilt([]FLOAT{1, 2}, nil, PadOdd, -1), []FLOAT{1, 2})
+	check.EqEps(t, IIRFiltFilt([]FLOAT{5}, sos, PadOdd, -1), []FLOAT{5}, 1e-5)
+	check.EqEps(t, FIRFiltFilt([]FLOAT{5}, []FLOAT{0.5, 0.5}, PadEven, 10), []FLOAT{5}, 1e-5)
+}
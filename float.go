@@ -1,3 +1,5 @@
 package dsp
 
 type FLOAT = float32
+
+type COMPLEX = complex64
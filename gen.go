@@ -6,6 +6,7 @@ package main
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -13,19 +14,22 @@ func main() {
 	os.MkdirAll("dsp32/dsp", 0666)
 	os.MkdirAll("dsp64/dsp", 0666)
 
-	dsp, err := ioutil.ReadFile("dsp.go")
-	check(err)
-	dsp32 := strings.Replace(string(dsp), "FLOAT", "float32", -1)
-	dsp64 := strings.Replace(string(dsp), "FLOAT", "float64", -1)
-	check(ioutil.WriteFile("dsp32/dsp/dsp.go", []byte(dsp32), 0666))
-	check(ioutil.WriteFile("dsp64/dsp/dsp.go", []byte(dsp64), 0666))
+	dsp32 := strings.NewReplacer("FLOAT", "float32", "COMPLEX", "complex64")
+	dsp64 := strings.NewReplacer("FLOAT", "float64", "COMPLEX", "complex128")
 
-	dspTest, err := ioutil.ReadFile("dsp_test.go")
+	files, err := filepath.Glob("*.go")
 	check(err)
-	dspTest32 := strings.Replace(string(dspTest), "FLOAT", "float32", -1)
-	dspTest64 := strings.Replace(string(dspTest), "FLOAT", "float64", -1)
-	check(ioutil.WriteFile("dsp32/dsp/dsp_test.go", []byte(dspTest32), 0666))
-	check(ioutil.WriteFile("dsp64/dsp/dsp_test.go", []byte(dspTest64), 0666))
+	for _, file := range files {
+		if file == "gen.go" || file == "float.go" {
+			continue
+		}
+		code, err := ioutil.ReadFile(file)
+		check(err)
+		code32 := dsp32.Replace(string(code))
+		code64 := dsp64.Replace(string(code))
+		check(ioutil.WriteFile(filepath.Join("dsp32", "dsp", file), []byte(code32), 0666))
+		check(ioutil.WriteFile(filepath.Join("dsp64", "dsp", file), []byte(code64), 0666))
+	}
 }
 
 func check(err error) {
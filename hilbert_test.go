@@ -0,0 +1,51 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func cosine(n int, amplitude, freq, sampleRate float64) []FLOAT {
+	x := make([]FLOAT, n)
+	for i := range x {
+		x[i] = FLOAT(amplitude * math.Cos(2*math.Pi*freq*float64(i)/sampleRate))
+	}
+	return x
+}
+
+func TestHilbertTurnsCosineIntoSine(t *testing.T) {
+	for _, n := range []int{100, 101} {
+		check.EqEps(t, Hilbert(cosine(n, 2, 5, float64(n))), sine(n, 2, 5, float64(n)), 1e-4)
+	}
+}
+
+func TestAnalyticSignalKeepsRealPart(t *testing.T) {
+	x := realTestSignal(33)
+	check.EqEps(t, RealParts(AnalyticSignal(x)), x, 1e-5)
+	check.Eq(t, AnalyticSignal(nil), nil)
+}
+
+func TestEnvelopeOfModulatedCarrier(t *testing.T) {
+	n := 1000
+	modulation := AddOffset(cosine(n, 0.5, 3, 1000), 1)
+	x := Mul(modulation, cosine(n, 1, 100, 1000))
+	check.EqEps(t, Envelope(x), modulation, 1e-4)
+}
+
+func TestInstantaneousPhaseAndFrequency(t *testing.T) {
+	n := 500
+	x := cosine(n, 1, 20, 1000)
+	phase := InstantaneousPhase(x)
+	for i := range phase {
+		check.EqEps(t, phase[i], 2*math.Pi*20*float64(i)/1000, 1e-3)
+	}
+
+	freq := InstantaneousFrequency(x, 1000)
+	check.Eq(t, len(freq), n-1)
+	for i := range freq {
+		check.EqEps(t, freq[i], 20, 1e-2)
+	}
+	check.Eq(t, InstantaneousFrequency(nil, 1000), nil)
+}
@@ -0,0 +1,633 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// BandType selects the kind of frequency band that an IIR filter design
+// passes.
+type BandType int
+
+const (
+	// Lowpass passes the frequencies below a single cutoff frequency.
+	Lowpass BandType = iota
+
+	// Highpass passes the frequencies above a single cutoff frequency.
+	Highpass
+
+	// Bandpass passes the frequencies between two cutoff frequencies.
+	Bandpass
+
+	// Bandstop blocks the frequencies between two cutoff frequencies.
+	Bandstop
+)
+
+// Butterworth designs a digital Butterworth filter of the given order, which
+// has a maximally flat pass band and a monotonic response. The response is
+// -3 dB at the cutoff frequencies.
+// band selects the type of filter, Lowpass and Highpass take one cutoff
+// frequency, Bandpass and Bandstop take the lower and upper edge of the band.
+// Band filters have twice the given order. The cutoff frequencies are in Hz for
+// signals sampled at sampleRate Hz, they must lie between 0 and sampleRate/2,
+// exclusively.
+// The filter is returned as second order sections that can be used with
+// IIRFilter or NewBiquadCascade. It is designed as an analog filter and turned
+// into a digital filter with the bilinear transform, the cutoff frequencies are
+// pre-warped so that they are exact in the digital filter.
+// If the order is < 1 or the cutoff frequencies are invalid, nil is returned.
+//
+// E.g. a 4th order lowpass at 1 kHz for signals sampled at 48 kHz is
+//
+//	Butterworth(4, Lowpass, 48000, 1000)
+func Butterworth(order int, band BandType, sampleRate FLOAT, cutoffs ...FLOAT) []Biquad {
+	if order < 1 {
+		return nil
+	}
+	return designIIR(butterworthPrototype(order), band, sampleRate, cutoffs)
+}
+
+// Chebyshev1 designs a digital Chebyshev type I filter, which has ripples of
+// ripple dB in the pass band and a monotonic stop band. It is steeper than a
+// Butterworth filter of the same order. The response at the cutoff frequencies
+// is -ripple dB, i.e. they are the edges of the pass band.
+// See Butterworth for the other parameters. If ripple <= 0, nil is returned.
+func Chebyshev1(order int, ripple FLOAT, band BandType, sampleRate FLOAT, cutoffs ...FLOAT) []Biquad {
+	if order < 1 || ripple <= 0 {
+		return nil
+	}
+	return designIIR(chebyshev1Prototype(order, float64(ripple)), band, sampleRate, cutoffs)
+}
+
+// Chebyshev2 designs a digital Chebyshev type II (inverse Chebyshev) filter,
+// which has a monotonic pass band and a stop band that is at least attenuation
+// dB down. The response at the cutoff frequencies is -attenuation dB, i.e. they
+// are the edges of the stop band.
+// See Butterworth for the other parameters. If attenuation <= 0, nil is
+// returned.
+func Chebyshev2(order int, attenuation FLOAT, band BandType, sampleRate FLOAT, cutoffs ...FLOAT) []Biquad {
+	if order < 1 || attenuation <= 0 {
+		return nil
+	}
+	return designIIR(chebyshev2Prototype(order, float64(attenuation)), band, sampleRate, cutoffs)
+}
+
+// Elliptic designs a digital elliptic (Cauer) filter, which has ripples of
+// ripple dB in the pass band and a stop band that is at least attenuation dB
+// down. It has the steepest transition of all designs of the same order. The
+// response at the cutoff frequencies is -ripple dB, i.e. they are the edges of
+// the pass band.
+// See Butterworth for the other parameters. If ripple <= 0 or attenuation <=
+// ripple, nil is returned.
+func Elliptic(order int, ripple, attenuation FLOAT, band BandType, sampleRate FLOAT, cutoffs ...FLOAT) []Biquad {
+	if order < 1 || ripple <= 0 || attenuation <= ripple {
+		return nil
+	}
+	proto := ellipticPrototype(order, float64(ripple), float64(attenuation))
+	return designIIR(proto, band, sampleRate, cutoffs)
+}
+
+// Bessel designs a digital Bessel filter, which has a maximally flat group
+// delay in the pass band of the analog prototype, i.e. it preserves the shape
+// of pulses with little overshoot. The response at the cutoff frequencies is
+// -3 dB. The bilinear transform does not preserve the flat group delay at high
+// frequencies, so the cutoff should be well below sampleRate/2.
+// See Butterworth for the other parameters.
+func Bessel(order int, band BandType, sampleRate FLOAT, cutoffs ...FLOAT) []Biquad {
+	if order < 1 {
+		return nil
+	}
+	return designIIR(besselPrototype(order), band, sampleRate, cutoffs)
+}
+
+// zpk is a filter given by its zeros, poles and gain.
+type zpk struct {
+	z, p []complex128
+	k    float64
+}
+
+// designIIR turns the analog lowpass prototype with a cutoff of 1 rad/s into a
+// digital filter of the given band type.
+func designIIR(proto zpk, band BandType, sampleRate FLOAT, cutoffs []FLOAT) []Biquad {
+	wanted := 1
+	if band == Bandpass || band == Bandstop {
+		wanted = 2
+	}
+	if len(cutoffs) != wanted {
+		return nil
+	}
+	fs := float64(sampleRate)
+	warped := make([]float64, len(cutoffs))
+	for i, f := range cutoffs {
+		if f <= 0 || float64(f) >= fs/2 || i > 0 && f <= cutoffs[i-1] {
+			return nil
+		}
+		warped[i] = 2 * fs * math.Tan(math.Pi*float64(f)/fs)
+	}
+
+	var analog zpk
+	switch band {
+	case Lowpass:
+		analog = lowpassToLowpass(proto, warped[0])
+	case Highpass:
+		analog = lowpassToHighpass(proto, warped[0])
+	case Bandpass:
+		analog = lowpassToBandpass(proto, math.Sqrt(warped[0]*warped[1]), warped[1]-warped[0])
+	case Bandstop:
+		analog = lowpassToBandstop(proto, math.Sqrt(warped[0]*warped[1]), warped[1]-warped[0])
+	default:
+		return nil
+	}
+	return zpkToSOS(bilinear(analog, fs))
+}
+
+func butterworthPrototype(n int) zpk {
+	p := make([]complex128, n)
+	for i := range p {
+		m := float64(2*i - n + 1)
+		p[i] = -cmplxExp(math.Pi * m / float64(2*n))
+	}
+	return zpk{p: p, k: 1}
+}
+
+func chebyshev1Prototype(n int, ripple float64) zpk {
+	eps := math.Sqrt(math.Pow(10, ripple/10) - 1)
+	mu := math.Asinh(1/eps) / float64(n)
+	p := make([]complex128, n)
+	for i := range p {
+		theta := math.Pi * float64(2*i-n+1) / float64(2*n)
+		p[i] = -cmplx.Sinh(complex(mu, theta))
+	}
+	k := real(rootProduct(p, 0))
+	if n%2 == 0 {
+		k /= math.Sqrt(1 + eps*eps)
+	}
+	return zpk{p: p, k: k}
+}
+
+func chebyshev2Prototype(n int, attenuation float64) zpk {
+	de := 1 / math.Sqrt(math.Pow(10, attenuation/10)-1)
+	mu := math.Asinh(1/de) / float64(n)
+
+	var z []complex128
+	for m := -n + 1; m < n; m += 2 {
+		// For odd orders, the zero for m = 0 would lie at infinity.
+		if m != 0 {
+			z = append(z, complex(0, 1/math.Sin(float64(m)*math.Pi/float64(2*n))))
+		}
+	}
+	p := make([]complex128, n)
+	for i := range p {
+		b := -cmplxExp(math.Pi * float64(2*i-n+1) / float64(2*n))
+		p[i] = 1 / complex(math.Sinh(mu)*real(b), math.Cosh(mu)*imag(b))
+	}
+	k := real(rootProduct(p, 0) / rootProduct(z, 0))
+	return zpk{z: z, p: p, k: k}
+}
+
+func ellipticPrototype(n int, ripple, attenuation float64) zpk {
+	epsSq := math.Pow(10, ripple/10) - 1
+	if n == 1 {
+		p := -math.Sqrt(1 / epsSq)
+		return zpk{p: []complex128{complex(p, 0)}, k: -p}
+	}
+
+	eps := math.Sqrt(epsSq)
+	ck1Sq := epsSq / (math.Pow(10, attenuation/10) - 1)
+	m := ellipticDegree(n, ck1Sq)
+	capK := ellipticK(m)
+	r := inverseJacobiSC1(1/eps, ck1Sq)
+	v0 := capK * r / (float64(n) * ellipticK(ck1Sq))
+	sv, cv, dv := jacobiElliptic(v0, 1-m)
+
+	var z, p []complex128
+	for j := 1 - n%2; j < n; j += 2 {
+		s, c, d := jacobiElliptic(float64(j)*capK/float64(n), m)
+		if j != 0 {
+			zj := complex(0, 1/(math.Sqrt(m)*s))
+			z = append(z, zj, cmplx.Conj(zj))
+		}
+		pj := -complex(c*d*sv*cv, s*dv) / complex(1-(d*sv)*(d*sv), 0)
+		if j == 0 {
+			p = append(p, pj)
+		} else {
+			p = append(p, pj, cmplx.Conj(pj))
+		}
+	}
+	k := real(rootProduct(p, 0) / rootProduct(z, 0))
+	if n%2 == 0 {
+		k /= math.Sqrt(1 + epsSq)
+	}
+	return zpk{z: z, p: p, k: k}
+}
+
+func besselPrototype(n int) zpk {
+	// The reverse Bessel polynomial has the coefficients
+	// (2n-k)! / (2^(n-k) * k! * (n-k)!) for s^k.
+	c := make([]complex128, n+1)
+	for k := 0; k <= n; k++ {
+		lg := func(x int) float64 {
+			v, _ := math.Lgamma(float64(x + 1))
+			return v
+		}
+		v := lg(2*n-k) - float64(n-k)*math.Ln2 - lg(k) - lg(n-k)
+		c[n-k] = complex(math.Exp(v), 0)
+	}
+	p := polyRoots(c)
+
+	// The polynomial is normalized for a group delay of 1 s, scale the poles
+	// so that the response is -3 dB at 1 rad/s instead.
+	gain := func(w float64) float64 {
+		return cmplx.Abs(rootProduct(p, 0) / rootProduct(p, complex(0, w)))
+	}
+	lo, hi := 0.0, 1.0
+	for gain(hi) > math.Sqrt(0.5) {
+		hi *= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if gain(mid) > math.Sqrt(0.5) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	for i := range p {
+		p[i] /= complex(lo, 0)
+	}
+	return zpk{p: p, k: real(rootProduct(p, 0))}
+}
+
+// rootProduct returns the product of all s - r[i], i.e. the value at s of the
+// monic polynomial with the roots r.
+func rootProduct(r []complex128, s complex128) complex128 {
+	prod := complex(1, 0)
+	for _, v := range r {
+		prod *= s - v
+	}
+	return prod
+}
+
+func lowpassToLowpass(f zpk, w float64) zpk {
+	wc := complex(w, 0)
+	z := make([]complex128, len(f.z))
+	for i := range z {
+		z[i] = f.z[i] * wc
+	}
+	p := make([]complex128, len(f.p))
+	for i := range p {
+		p[i] = f.p[i] * wc
+	}
+	degree := len(p) - len(z)
+	return zpk{z: z, p: p, k: f.k * math.Pow(w, float64(degree))}
+}
+
+func lowpassToHighpass(f zpk, w float64) zpk {
+	wc := complex(w, 0)
+	z := make([]complex128, len(f.z), len(f.p))
+	for i := range z {
+		z[i] = wc / f.z[i]
+	}
+	p := make([]complex128, len(f.p))
+	for i := range p {
+		p[i] = wc / f.p[i]
+	}
+	// The zeros at infinity move to the origin.
+	for len(z) < len(p) {
+		z = append(z, 0)
+	}
+	k := f.k * real(rootProduct(f.z, 0)/rootProduct(f.p, 0))
+	return zpk{z: z, p: p, k: k}
+}
+
+func lowpassToBandpass(f zpk, w0, bw float64) zpk {
+	split := func(r []complex128) []complex128 {
+		s := make([]complex128, 0, 2*len(r))
+		for _, v := range r {
+			v *= complex(bw/2, 0)
+			d := cmplx.Sqrt(v*v - complex(w0*w0, 0))
+			s = append(s, v+d, v-d)
+		}
+		return s
+	}
+	z, p := split(f.z), split(f.p)
+	degree := len(f.p) - len(f.z)
+	for i := 0; i < degree; i++ {
+		z = append(z, 0)
+	}
+	return zpk{z: z, p: p, k: f.k * math.Pow(bw, float64(degree))}
+}
+
+func lowpassToBandstop(f zpk, w0, bw float64) zpk {
+	split := func(r []complex128) []complex128 {
+		s := make([]complex128, 0, 2*len(r))
+		for _, v := range r {
+			v = complex(bw/2, 0) / v
+			d := cmplx.Sqrt(v*v - complex(w0*w0, 0))
+			s = append(s, v+d, v-d)
+		}
+		return s
+	}
+	z, p := split(f.z), split(f.p)
+	// The zeros at infinity move to the center of the stop band.
+	degree := len(f.p) - len(f.z)
+	for i := 0; i < degree; i++ {
+		z = append(z, complex(0, w0), complex(0, -w0))
+	}
+	k := f.k * real(rootProduct(f.z, 0)/rootProduct(f.p, 0))
+	return zpk{z: z, p: p, k: k}
+}
+
+// bilinear maps the analog filter f to a digital filter for the sample rate
+// fs with the bilinear transform s = 2*fs * (z-1)/(z+1).
+func bilinear(f zpk, fs float64) zpk {
+	fs2 := complex(2*fs, 0)
+	z := make([]complex128, len(f.z), len(f.p))
+	for i := range z {
+		z[i] = (fs2 + f.z[i]) / (fs2 - f.z[i])
+	}
+	p := make([]complex128, len(f.p))
+	for i := range p {
+		p[i] = (fs2 + f.p[i]) / (fs2 - f.p[i])
+	}
+	// The zeros at infinity move to the Nyquist frequency.
+	for len(z) < len(p) {
+		z = append(z, -1)
+	}
+	k := f.k * real(rootProduct(f.z, fs2)/rootProduct(f.p, fs2))
+	return zpk{z: z, p: p, k: k}
+}
+
+// zpkToSOS groups the zeros and poles of the digital filter f into second
+// order sections. Complex roots are paired with their conjugates, real roots
+// with each other. The poles closest to the unit circle go into the last
+// sections and each pair of poles gets the zeros closest to it, which keeps
+// the gain of the individual sections moderate. The overall gain is applied in
+// the first section.
+func zpkToSOS(f zpk) []Biquad {
+	n := (len(f.p) + 1) / 2
+	if len(f.z) > len(f.p) {
+		n = (len(f.z) + 1) / 2
+	}
+	if n == 0 {
+		return []Biquad{{B0: FLOAT(f.k)}}
+	}
+	zc, zr := splitRoots(f.z, 2*n)
+	pc, pr := splitRoots(f.p, 2*n)
+
+	sections := make([]Biquad, n)
+	for i := n - 1; i >= 0; i-- {
+		var p1, p2 complex128
+		ic, ir := -1, -1
+		best := math.Inf(1)
+		for j, v := range pc {
+			if d := math.Abs(1 - cmplx.Abs(v)); d < best {
+				best, ic = d, j
+			}
+		}
+		for j, v := range pr {
+			if d := math.Abs(1 - math.Abs(v)); d < best {
+				best, ic, ir = d, -1, j
+			}
+		}
+		if ic >= 0 {
+			p1 = pc[ic]
+			p2 = cmplx.Conj(p1)
+			pc = removeComplex(pc, ic)
+		} else {
+			p1 = complex(pr[ir], 0)
+			pr = removeReal(pr, ir)
+			j := nearestReal(pr, p1)
+			p2 = complex(pr[j], 0)
+			pr = removeReal(pr, j)
+		}
+
+		var z1, z2 complex128
+		ic, ir = -1, -1
+		best = math.Inf(1)
+		for j, v := range zc {
+			if d := cmplx.Abs(v - p1); d < best {
+				best, ic = d, j
+			}
+		}
+		for j, v := range zr {
+			if d := cmplx.Abs(complex(v, 0) - p1); d < best {
+				best, ic, ir = d, -1, j
+			}
+		}
+		if ic >= 0 {
+			z1 = zc[ic]
+			z2 = cmplx.Conj(z1)
+			zc = removeComplex(zc, ic)
+		} else {
+			z1 = complex(zr[ir], 0)
+			zr = removeReal(zr, ir)
+			target := p1
+			if imag(p1) == 0 {
+				target = p2
+			}
+			j := nearestReal(zr, target)
+			z2 = complex(zr[j], 0)
+			zr = removeReal(zr, j)
+		}
+
+		sections[i] = Biquad{
+			B0: 1,
+			B1: FLOAT(-real(z1 + z2)),
+			B2: FLOAT(real(z1 * z2)),
+			A1: FLOAT(-real(p1 + p2)),
+			A2: FLOAT(real(p1 * p2)),
+		}
+	}
+	k := FLOAT(f.k)
+	sections[0].B0 *= k
+	sections[0].B1 *= k
+	sections[0].B2 *= k
+	return sections
+}
+
+// splitRoots separates the roots r into the complex ones with positive
+// imaginary parts, which stand for conjugate pairs, and the real ones. Roots at
+// the origin are added to make up count roots.
+func splitRoots(r []complex128, count int) (complexRoots []complex128, realRoots []float64) {
+	for _, v := range r {
+		if math.Abs(imag(v)) <= 1e-10*math.Max(1, cmplx.Abs(v)) {
+			realRoots = append(realRoots, real(v))
+		} else if imag(v) > 0 {
+			complexRoots = append(complexRoots, v)
+		}
+	}
+	for 2*len(complexRoots)+len(realRoots) < count {
+		realRoots = append(realRoots, 0)
+	}
+	return
+}
+
+func nearestReal(r []float64, to complex128) int {
+	best := -1
+	for i, v := range r {
+		if best == -1 || cmplx.Abs(complex(v, 0)-to) < cmplx.Abs(complex(r[best], 0)-to) {
+			best = i
+		}
+	}
+	return best
+}
+
+func removeComplex(r []complex128, i int) []complex128 {
+	return append(r[:i], r[i+1:]...)
+}
+
+func removeReal(r []float64, i int) []float64 {
+	return append(r[:i], r[i+1:]...)
+}
+
+// polyRoots returns the roots of the polynomial with the coefficients c,
+// starting with the highest power. It uses the Aberth-Ehrlich method, which
+// finds all roots simultaneously.
+func polyRoots(c []complex128) []complex128 {
+	for len(c) > 0 && c[0] == 0 {
+		c = c[1:]
+	}
+	var roots []complex128
+	for len(c) > 1 && c[len(c)-1] == 0 {
+		roots = append(roots, 0)
+		c = c[:len(c)-1]
+	}
+	n := len(c) - 1
+	if n < 1 {
+		return roots
+	}
+
+	// Start on a circle with the radius of the geometric mean of the roots,
+	// slightly rotated to avoid symmetric starting points.
+	radius := math.Pow(cmplx.Abs(c[n]/c[0]), 1/float64(n))
+	z := make([]complex128, n)
+	for i := range z {
+		z[i] = complex(radius, 0) * cmplxExp(2*math.Pi*float64(i)/float64(n)+0.4)
+	}
+
+	for iter := 0; iter < 500; iter++ {
+		converged := true
+		for i := range z {
+			p, dp := c[0], complex(0, 0)
+			for _, v := range c[1:] {
+				dp = dp*z[i] + p
+				p = p*z[i] + v
+			}
+			if p == 0 {
+				continue
+			}
+			ratio := p / dp
+			var sum complex128
+			for j := range z {
+				if j != i {
+					sum += 1 / (z[i] - z[j])
+				}
+			}
+			step := ratio / (1 - ratio*sum)
+			z[i] -= step
+			if cmplx.Abs(step) > 1e-14*math.Max(cmplx.Abs(z[i]), 1e-300) {
+				converged = false
+			}
+		}
+		if converged {
+			break
+		}
+	}
+	return append(roots, z...)
+}
+
+// ellipticK returns the complete elliptic integral of the first kind K(m) for
+// the parameter m = k².
+func ellipticK(m float64) float64 {
+	return math.Pi / (2 * agm(1, math.Sqrt(1-m)))
+}
+
+// ellipticKComplement returns K(1-m1), which is accurate for small m1.
+func ellipticKComplement(m1 float64) float64 {
+	return math.Pi / (2 * agm(1, math.Sqrt(m1)))
+}
+
+// agm returns the arithmetic-geometric mean of a and b.
+func agm(a, b float64) float64 {
+	for i := 0; i < 100 && math.Abs(a-b) > 1e-15*a; i++ {
+		a, b = (a+b)/2, math.Sqrt(a*b)
+	}
+	return a
+}
+
+// jacobiElliptic returns the Jacobi elliptic functions sn, cn and dn of u for
+// the parameter m, computed with the descending Landen transformation.
+func jacobiElliptic(u, m float64) (sn, cn, dn float64) {
+	if m < 1e-12 {
+		return math.Sin(u), math.Cos(u), 1
+	}
+	if m > 1-1e-12 {
+		sech := 1 / math.Cosh(u)
+		return math.Tanh(u), sech, sech
+	}
+	a := []float64{1}
+	c := []float64{math.Sqrt(m)}
+	b := math.Sqrt(1 - m)
+	for i := 0; i < 20 && math.Abs(c[i]) > 1e-16; i++ {
+		an, bn := a[i], b
+		a = append(a, (an+bn)/2)
+		c = append(c, (an-bn)/2)
+		b = math.Sqrt(an * bn)
+	}
+	last := len(a) - 1
+	phi := math.Ldexp(a[last]*u, last)
+	for i := last; i > 0; i-- {
+		phi = (phi + math.Asin(c[i]*math.Sin(phi)/a[i])) / 2
+	}
+	sn, cn = math.Sin(phi), math.Cos(phi)
+	return sn, cn, math.Sqrt(1 - m*sn*sn)
+}
+
+// ellipticDegree solves the degree equation n*K(m)/K(1-m) = K(m1)/K(1-m1) for
+// m, using nomes.
+func ellipticDegree(n int, m1 float64) float64 {
+	q1 := math.Exp(-math.Pi * ellipticKComplement(m1) / ellipticK(m1))
+	q := math.Pow(q1, 1/float64(n))
+	var num float64
+	for i := 0; i <= 7; i++ {
+		num += math.Pow(q, float64(i*(i+1)))
+	}
+	den := 1.0
+	for i := 1; i <= 8; i++ {
+		den += 2 * math.Pow(q, float64(i*i))
+	}
+	return 16 * q * math.Pow(num/den, 4)
+}
+
+// inverseJacobiSN returns the inverse of the Jacobi elliptic function sn for
+// the complex argument w and the parameter m, using the Landen transformation.
+func inverseJacobiSN(w complex128, m float64) complex128 {
+	k := math.Sqrt(m)
+	if k >= 1 {
+		return cmplx.Atanh(w)
+	}
+	complement := func(x complex128) complex128 {
+		return cmplx.Sqrt((1 - x) * (1 + x))
+	}
+	ks := []float64{k}
+	for len(ks) < 20 && ks[len(ks)-1] != 0 {
+		kp := math.Sqrt((1 - ks[len(ks)-1]) * (1 + ks[len(ks)-1]))
+		ks = append(ks, (1-kp)/(1+kp))
+	}
+	K := math.Pi / 2
+	for _, v := range ks[1:] {
+		K *= 1 + v
+	}
+	for i := 0; i+1 < len(ks); i++ {
+		kn, next := complex(ks[i], 0), complex(ks[i+1], 0)
+		w = 2 * w / ((1 + next) * (1 + complement(kn*w)))
+	}
+	return complex(K*2/math.Pi, 0) * cmplx.Asin(w)
+}
+
+// inverseJacobiSC1 returns the real inverse of the Jacobi elliptic function sc
+// of w for the complementary parameter 1-m.
+func inverseJacobiSC1(w, m float64) float64 {
+	return imag(inverseJacobiSN(complex(0, w), m))
+}
@@ -0,0 +1,84 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestNotchResponse(t *testing.T) {
+	const fs = 1000
+	sos := Notch(50, 10, fs)
+	check.Eq(t, len(sos), 1)
+	gain := Magnitude(SOSFreqResponse(sos, []FLOAT{0, 45, 47.5, 50, 52.5, 200, 499}, fs))
+	check.EqEps(t, float64(gain[0]), 1, 1e-5)
+	check.EqEps(t, float64(gain[3]), 0, 1e-4)
+	check.EqEps(t, float64(gain[5]), 1, 1e-2)
+	check.EqEps(t, float64(gain[6]), 1, 1e-5)
+	check.Eq(t, gain[1] > 0.8, true)
+
+	// The -3 dB bandwidth is 50/10 = 5 Hz, the bilinear transform makes the
+	// edges slightly asymmetric.
+	check.EqEps(t, float64(gain[2]), math.Sqrt(0.5), 0.01)
+	check.EqEps(t, float64(gain[4]), math.Sqrt(0.5), 0.01)
+}
+
+func TestPeakIsComplementOfNotch(t *testing.T) {
+	const fs = 8000
+	freqs := FrequencyGrid(64, fs)
+	notch := SOSFreqResponse(Notch(1000, 5, fs), freqs, fs)
+	peak := SOSFreqResponse(Peak(1000, 5, fs), freqs, fs)
+	for i := range freqs {
+		check.EqEps(t, cmplx.Abs(complex128(notch[i]+peak[i])), 1, 1e-5, freqs[i])
+	}
+	gain := Magnitude(SOSFreqResponse(Peak(1000, 5, fs), []FLOAT{0, 1000}, fs))
+	check.EqEps(t, gain, []FLOAT{0, 1}, 1e-5)
+}
+
+func TestNotchFilterRemovesHum(t *testing.T) {
+	const fs = 1000
+	signal := AddOffset(sine(4000, 1, 7, fs), 2)
+	hum := sine(4000, 0.5, 50, fs)
+	y := NotchFilter(Add(signal, hum), 50, 5, fs)
+	check.Eq(t, len(y), len(signal))
+	// After the notch has settled, only the signal is left. The 7 Hz sine is
+	// shifted by the notch's phase, so compare the amplitudes.
+	check.EqEps(t, MaxValue(y[2000:])-2, 1, 0.02)
+	check.EqEps(t, MinValue(y[2000:])-2, -1, 0.02)
+	check.EqEps(t, y[0], signal[0]+hum[0], 1e-5)
+
+	peak := PeakFilter(Add(signal, hum), 50, 5, fs)
+	check.EqEps(t, MaxValue(peak[2000:]), 0.5, 0.03)
+}
+
+func TestCombRemovesHarmonics(t *testing.T) {
+	const fs = 1000
+	sos := Comb(60, 3, 30, fs)
+	check.Eq(t, len(sos), 4)
+	gain := Magnitude(SOSFreqResponse(sos, []FLOAT{0, 60, 120, 180, 240, 90, 300}, fs))
+	check.EqEps(t, gain[:5], []FLOAT{1, 0, 0, 0, 0}, 1e-3)
+	check.EqEps(t, float64(gain[5]), 1, 0.01)
+	check.EqEps(t, float64(gain[6]), 1, 0.01)
+
+	// All notches have the bandwidth 2 Hz.
+	edges := Magnitude(SOSFreqResponse(sos[3:], []FLOAT{239, 241}, fs))
+	check.EqEps(t, edges, []FLOAT{FLOAT(math.Sqrt(0.5)), FLOAT(math.Sqrt(0.5))}, 0.01)
+
+	// Harmonics above the Nyquist frequency are left out.
+	check.Eq(t, len(Comb(150, 10, 30, fs)), 3)
+
+	x := Add(sine(3000, 1, 60, fs), sine(3000, 1, 180, fs))
+	y := CombFilter(x, 60, 2, 10, fs)
+	check.EqEps(t, y[2000:], Repeat(0, 1000), 0.01)
+}
+
+func TestNotchInvalidParameters(t *testing.T) {
+	check.Eq(t, Notch(0, 10, 1000), nil)
+	check.Eq(t, Notch(500, 10, 1000), nil)
+	check.Eq(t, Peak(50, 0, 1000), nil)
+	check.Eq(t, Comb(50, -1, 10, 1000), nil)
+	check.Eq(t, NotchFilter([]FLOAT{1}, 50, 10, 0), nil)
+	check.Eq(t, CombFilter(nil, 50, 1, 10, 1000), []FLOAT{})
+}
@@ -0,0 +1,123 @@
+package dsp
+
+import "math"
+
+// OnePole is a first order IIR filter, the digital equivalent of an RC
+// element. As a Lowpass it is the exponential moving average (EMA)
+//
+//	y[n] = y[n-1] + alpha*(x[n] - y[n-1])
+//
+// and as a Highpass it returns x[n] minus the lowpass output, which removes
+// the DC offset of a signal (DC blocker). Lowpass and highpass with the same
+// alpha add up to the input.
+// The filter keeps its state between calls to Process, so a long signal can be
+// filtered in chunks and gives the same result as filtering it at once.
+type OnePole struct {
+	band  BandType
+	alpha float64
+	// lowpass is the last output of the lowpass part.
+	lowpass float64
+}
+
+// NewOnePole creates a Lowpass or Highpass one-pole filter with the given
+// smoothing factor, 0 < alpha <= 1. Small alphas smooth more, alpha = 1 does
+// not smooth at all. Use AlphaFromTimeConstant or AlphaFromCutoff to compute
+// alpha. The state is reset to zero.
+// If the band or alpha are invalid, nil is returned.
+func NewOnePole(band BandType, alpha FLOAT) *OnePole {
+	if !validOnePole(band, alpha) {
+		return nil
+	}
+	return &OnePole{band: band, alpha: float64(alpha)}
+}
+
+func validOnePole(band BandType, alpha FLOAT) bool {
+	return (band == Lowpass || band == Highpass) && alpha > 0 && alpha <= 1
+}
+
+// Alpha returns the filter's smoothing factor.
+func (f *OnePole) Alpha() FLOAT {
+	return FLOAT(f.alpha)
+}
+
+// ProcessSample filters the single sample x and returns the output sample.
+func (f *OnePole) ProcessSample(x FLOAT) FLOAT {
+	v := float64(x)
+	f.lowpass += f.alpha * (v - f.lowpass)
+	if f.band == Highpass {
+		return FLOAT(v - f.lowpass)
+	}
+	return FLOAT(f.lowpass)
+}
+
+// Process filters the samples in x, continuing from the state that the last
+// call left behind, and returns the filtered samples. x is not modified.
+func (f *OnePole) Process(x []FLOAT) []FLOAT {
+	y := make([]FLOAT, len(x))
+	for i := range x {
+		y[i] = f.ProcessSample(x[i])
+	}
+	return y
+}
+
+// Reset sets the filter's state back to zero, as if no samples had been
+// processed.
+func (f *OnePole) Reset() {
+	f.lowpass = 0
+}
+
+// SetSteadyState sets the filter's state to the state that a constant input
+// of x would settle at, so that a signal starting at x does not cause a
+// transient at its start.
+func (f *OnePole) SetSteadyState(x FLOAT) {
+	f.lowpass = float64(x)
+}
+
+// OnePoleFilter returns a filtered with a Lowpass or Highpass OnePole filter,
+// see NewOnePole. The filter starts in the steady state for the first sample,
+// i.e. the lowpass output starts at a[0] and the highpass output at 0. The
+// result has the same length as a.
+// If the band or alpha are invalid, nil is returned.
+func OnePoleFilter(a []FLOAT, band BandType, alpha FLOAT) []FLOAT {
+	f := NewOnePole(band, alpha)
+	if f == nil {
+		return nil
+	}
+	if len(a) > 0 {
+		f.SetSteadyState(a[0])
+	}
+	return f.Process(a)
+}
+
+// EMA returns the exponential moving average over a with the smoothing factor
+// 0 < alpha <= 1. This is the OnePoleFilter with band Lowpass, the average
+// starts at a[0].
+// If alpha is invalid, nil is returned.
+func EMA(a []FLOAT, alpha FLOAT) []FLOAT {
+	return OnePoleFilter(a, Lowpass, alpha)
+}
+
+// AlphaFromTimeConstant returns the smoothing factor of a OnePole filter that
+// behaves like an RC element with the given time constant (R*C) in seconds,
+// for samples taken at sampleRate. The lowpass step response reaches 1-1/e, or
+// about 63%, after timeConstant seconds.
+// A time constant of 0 gives alpha = 1, i.e. no smoothing. For a negative time
+// constant or a sample rate <= 0, 0 is returned, which is not a valid alpha.
+func AlphaFromTimeConstant(timeConstant, sampleRate FLOAT) FLOAT {
+	if timeConstant < 0 || sampleRate <= 0 {
+		return 0
+	}
+	return FLOAT(1 - math.Exp(-1/(float64(timeConstant)*float64(sampleRate))))
+}
+
+// AlphaFromCutoff returns the smoothing factor of a OnePole filter that
+// behaves like an RC element with the given -3 dB cutoff frequency, i.e. with
+// the time constant 1/(2*pi*cutoff). The digital filter matches its analog
+// counterpart for cutoffs well below the Nyquist frequency sampleRate/2.
+// For a cutoff or sample rate <= 0, 0 is returned, which is not a valid alpha.
+func AlphaFromCutoff(cutoff, sampleRate FLOAT) FLOAT {
+	if cutoff <= 0 || sampleRate <= 0 {
+		return 0
+	}
+	return AlphaFromTimeConstant(FLOAT(1/(2*math.Pi*float64(cutoff))), sampleRate)
+}
@@ -0,0 +1,74 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/gonutz/check"
+)
+
+func TestSavitzkyGolayCoefficients(t *testing.T) {
+	check.EqEps(t,
+		SavitzkyGolayCoefficients(5, 2, 0, 1),
+		Scale([]FLOAT{-3, 12, 17, 12, -3}, 1.0/35),
+		1e-6,
+	)
+	// The first derivative taps are reversed for convolution.
+	check.EqEps(t,
+		SavitzkyGolayCoefficients(5, 2, 1, 0.5),
+		Scale([]FLOAT{2, 1, 0, -1, -2}, 1.0/10/0.5),
+		1e-6,
+	)
+	check.Eq(t, SavitzkyGolayCoefficients(5, 1, 2, 1), []FLOAT{0, 0, 0, 0, 0})
+	check.Eq(t, SavitzkyGolayCoefficients(4, 1, 0, 1), nil)
+}
+
+func TestSavitzkyGolayReproducesPolynomials(t *testing.T) {
+	// x(t) = 2t³ - t² + 3 sampled every 0.1.
+	const dt = 0.1
+	x := make([]FLOAT, 30)
+	dx := make([]FLOAT, 30)
+	ddx := make([]FLOAT, 30)
+	for i := range x {
+		t := dt * float64(i)
+		x[i] = FLOAT(2*t*t*t - t*t + 3)
+		dx[i] = FLOAT(6*t*t - 2*t)
+		ddx[i] = FLOAT(12*t - 2)
+	}
+	check.EqEps(t, SavitzkyGolay(x, 7, 3, 0, dt), x, 1e-4)
+	check.EqEps(t, SavitzkyGolay(x, 7, 3, 1, dt), dx, 1e-3)
+	check.EqEps(t, SavitzkyGolay(x, 9, 4, 2, dt), ddx, 1e-2)
+	check.EqEps(t, SavitzkyGolay(x, 7, 2, 3, dt), Repeat(0, 30), 0)
+}
+
+func TestSavitzkyGolaySmoothsNoise(t *testing.T) {
+	clean := sine(500, 1, 10, 1000)
+	noisy := Add(clean, Scale(noise(500), 0.2))
+	smooth := SavitzkyGolay(noisy, 31, 3, 0, 1)
+	check.Eq(t, len(smooth), len(noisy))
+	errBefore := Average(Abs(Sub(noisy, clean)))
+	errAfter := Average(Abs(Sub(smooth, clean)))
+	check.Eq(t, errAfter < errBefore/3, true, errBefore, " ", errAfter)
+
+	// The interior matches filtering with the coefficients.
+	taps := SavitzkyGolayCoefficients(31, 3, 0, 1)
+	check.EqEps(t, smooth[15:485], FIRFilter(noisy, taps, ConvolveSame)[15:485], 1e-5)
+}
+
+func TestSavitzkyGolayShortSignals(t *testing.T) {
+	// A line through all samples is fitted for the 3 samples.
+	check.EqEps(t, SavitzkyGolay([]FLOAT{1, 3, 5}, 11, 1, 0, 1), []FLOAT{1, 3, 5}, 1e-5)
+	check.EqEps(t, SavitzkyGolay([]FLOAT{1, 3, 5}, 11, 1, 1, 2), []FLOAT{1, 1, 1}, 1e-5)
+	// The order is limited to a line through 2 samples.
+	check.EqEps(t, SavitzkyGolay([]FLOAT{1, 2}, 5, 3, 0, 1), []FLOAT{1, 2}, 1e-5)
+	check.Eq(t, SavitzkyGolay([]FLOAT{1, 2}, 5, 3, 2, 1), []FLOAT{0, 0})
+	check.Eq(t, SavitzkyGolay(nil, 5, 3, 0, 1), []FLOAT{})
+}
+
+func TestSavitzkyGolayInvalidParameters(t *testing.T) {
+	x := []FLOAT{1, 2, 3, 4, 5}
+	check.Eq(t, SavitzkyGolay(x, 0, 0, 0, 1), nil)
+	check.Eq(t, SavitzkyGolay(x, 4, 2, 0, 1), nil)
+	check.Eq(t, SavitzkyGolay(x, 5, 5, 0, 1), nil)
+	check.Eq(t, SavitzkyGolay(x, 5, -1, 0, 1), nil)
+	check.Eq(t, SavitzkyGolay(x, 5, 2, -1, 1), nil)
+}
@@ -0,0 +1,135 @@
+package dsp
+
+import "math"
+
+// SpectrumScaling selects the physical unit of a one-sided spectrum. The
+// examples assume the signal is measured in volts.
+type SpectrumScaling int
+
+const (
+	// PeakAmplitude scales the spectrum so that a sinusoid of amplitude A
+	// shows up with value A (Vpk).
+	PeakAmplitude SpectrumScaling = iota
+
+	// RMSAmplitude scales the spectrum so that a sinusoid of amplitude A shows
+	// up with its RMS value A/sqrt(2) (Vrms).
+	RMSAmplitude
+
+	// PowerSpectrum scales the spectrum so that a sinusoid of amplitude A shows
+	// up with its power A*A/2 (Vrms²).
+	PowerSpectrum
+
+	// PowerSpectralDensity scales the spectrum to power per Hz (V²/Hz). This is
+	// the right scaling for noise, its values summed over all bins and
+	// multiplied by the bin width give the signal's mean square.
+	PowerSpectralDensity
+)
+
+// IsPower returns true for scalings of squared values and false for amplitude
+// scalings.
+func (s SpectrumScaling) IsPower() bool {
+	return s == PowerSpectrum || s == PowerSpectralDensity
+}
+
+// ToDB converts values in scaling s to decibels relative to reference. Power
+// values are converted as 10*log10(v/reference), amplitude values as
+// 20*log10(v/reference). If reference <= 0, a reference of 1 is used. Values of
+// 0 become -Inf.
+func (s SpectrumScaling) ToDB(values []FLOAT, reference FLOAT) []FLOAT {
+	if reference <= 0 {
+		reference = 1
+	}
+	factor := 20.0
+	if s.IsPower() {
+		factor = 10.0
+	}
+	db := make([]FLOAT, len(values))
+	for i := range db {
+		db[i] = FLOAT(factor * math.Log10(float64(values[i]/reference)))
+	}
+	return db
+}
+
+// Spectrum returns the one-sided spectrum of the signal x, sampled at
+// sampleRate Hz, in the unit selected by scaling. The values are corrected for
+// the window's coherent gain (amplitude and power scalings) or its equivalent
+// noise bandwidth (density scaling), see CoherentGain and ENBW.
+// freqs holds the frequency in Hz of each of the len(x)/2+1 bins in values.
+// window must either be nil for a rectangular window or have the same length
+// as x, otherwise nil is returned. Periodic windows are the usual choice for
+// spectral analysis. If the window sums to 0, e.g. if it is all zeros, it has
+// no gain to correct for and nil is returned.
+// For empty x, empty slices are returned.
+func Spectrum(x []FLOAT, sampleRate FLOAT, window []FLOAT, scaling SpectrumScaling) (freqs, values []FLOAT) {
+	if len(x) == 0 || window != nil && len(window) != len(x) {
+		return nil, nil
+	}
+
+	if window == nil {
+		window = Repeat(1, len(x))
+	}
+	values = Power(RFFT(Mul(x, window)))
+	if !scaleSpectrum(values, len(x), sampleRate, window, scaling) {
+		return nil, nil
+	}
+	return SpectrumFrequencies(len(x), sampleRate), values
+}
+
+// SpectrumFrequencies returns the frequencies in Hz of the n/2+1 bins in the
+// one-sided spectrum of a signal of length n, sampled at sampleRate Hz. If
+// n <= 0, an empty slice is returned.
+func SpectrumFrequencies(n int, sampleRate FLOAT) []FLOAT {
+	if n <= 0 {
+		return nil
+	}
+	f := make([]FLOAT, n/2+1)
+	for i := range f {
+		f[i] = FLOAT(float64(i) * float64(sampleRate) / float64(n))
+	}
+	return f
+}
+
+// scaleSpectrum converts the squared magnitudes of the one-sided DFT of a
+// windowed signal of length n in place to the given scaling. If the window sums
+// to 0, power is left unchanged and false is returned.
+func scaleSpectrum(power []FLOAT, n int, sampleRate FLOAT, window []FLOAT, scaling SpectrumScaling) bool {
+	var sum, sumSquares float64
+	for _, w := range window {
+		sum += float64(w)
+		sumSquares += float64(w) * float64(w)
+	}
+	if sum == 0 {
+		return false
+	}
+
+	var scale float64
+	if scaling == PowerSpectralDensity {
+		scale = 1 / (float64(sampleRate) * sumSquares)
+	} else {
+		scale = 1 / (sum * sum)
+	}
+
+	for i := range power {
+		// The one-sided spectrum folds the energy of the negative frequencies
+		// onto the positive ones. DC and Nyquist exist only once.
+		s := 2 * scale
+		if i == 0 || 2*i == n {
+			s = scale
+		}
+		v := float64(power[i]) * s
+		switch scaling {
+		case PeakAmplitude:
+			// The RMS value of a sinusoid is its peak divided by sqrt(2), for
+			// DC both are the same.
+			if i == 0 || 2*i == n {
+				v = math.Sqrt(v)
+			} else {
+				v = math.Sqrt(2 * v)
+			}
+		case RMSAmplitude:
+			v = math.Sqrt(v)
+		}
+		power[i] = FLOAT(v)
+	}
+	return true
+}
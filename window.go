@@ -0,0 +1,258 @@
+package dsp
+
+import "math"
+
+// WindowSymmetry selects between the two common variants of window functions.
+type WindowSymmetry int
+
+const (
+	// Symmetric windows are symmetric around their center, the first and last
+	// values are the same. Use these for filter design.
+	Symmetric WindowSymmetry = iota
+
+	// Periodic windows are one period of a periodic function, i.e. the first
+	// value of the next period would follow after the last value. They are
+	// computed as a symmetric window of length n+1 with the last value
+	// dropped. Use these for spectral analysis.
+	Periodic
+)
+
+// Hann returns a Hann (raised cosine) window of length n. If n <= 0 the
+// returned slice is empty.
+func Hann(n int, sym WindowSymmetry) []FLOAT {
+	return cosineWindow(n, sym, 0.5, 0.5)
+}
+
+// Hamming returns a Hamming window of length n. If n <= 0 the returned slice is
+// empty.
+func Hamming(n int, sym WindowSymmetry) []FLOAT {
+	return cosineWindow(n, sym, 0.54, 0.46)
+}
+
+// Blackman returns a Blackman window of length n. If n <= 0 the returned slice
+// is empty.
+func Blackman(n int, sym WindowSymmetry) []FLOAT {
+	return cosineWindow(n, sym, 0.42, 0.5, 0.08)
+}
+
+// BlackmanHarris returns a 4-term Blackman-Harris window of length n. Its
+// highest side lobe is at -92 dB. If n <= 0 the returned slice is empty.
+func BlackmanHarris(n int, sym WindowSymmetry) []FLOAT {
+	return cosineWindow(n, sym, 0.35875, 0.48829, 0.14128, 0.01168)
+}
+
+// FlatTop returns a flat-top window of length n. It has a very small
+// scalloping loss which makes it well suited for measuring the amplitudes of
+// sinusoids that do not lie exactly on a frequency bin. If n <= 0 the returned
+// slice is empty.
+func FlatTop(n int, sym WindowSymmetry) []FLOAT {
+	return cosineWindow(n, sym,
+		0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368)
+}
+
+// cosineWindow returns the generalized cosine window
+//
+//	w[k] = a[0] - a[1]*cos(2*pi*k/N) + a[2]*cos(4*pi*k/N) - ...
+//
+// with N = n-1 for symmetric and N = n for periodic windows.
+func cosineWindow(n int, sym WindowSymmetry, a ...float64) []FLOAT {
+	return symmetricWindow(n, sym, func(m int) []FLOAT {
+		w := make([]FLOAT, m)
+		for k := range w {
+			x := 2 * math.Pi * float64(k) / float64(m-1)
+			var sum float64
+			sign := 1.0
+			for j := range a {
+				sum += sign * a[j] * math.Cos(float64(j)*x)
+				sign = -sign
+			}
+			w[k] = FLOAT(sum)
+		}
+		return w
+	})
+}
+
+// Kaiser returns a Kaiser window of length n. The shape parameter beta trades
+// main lobe width for side lobe level, beta = 0 is a rectangular window, about
+// 5 is similar to Hamming and about 8.6 is similar to Blackman. If n <= 0 the
+// returned slice is empty.
+func Kaiser(n int, beta FLOAT, sym WindowSymmetry) []FLOAT {
+	b := float64(beta)
+	return symmetricWindow(n, sym, func(m int) []FLOAT {
+		w := make([]FLOAT, m)
+		denom := besselI0(b)
+		for k := range w {
+			x := 2*float64(k)/float64(m-1) - 1
+			w[k] = FLOAT(besselI0(b*math.Sqrt(1-x*x)) / denom)
+		}
+		return w
+	})
+}
+
+// Tukey returns a Tukey (tapered cosine) window of length n. alpha is the
+// fraction of the window inside the cosine tapers, alpha <= 0 gives a
+// rectangular window and alpha >= 1 gives a Hann window. If n <= 0 the returned
+// slice is empty.
+func Tukey(n int, alpha FLOAT, sym WindowSymmetry) []FLOAT {
+	if alpha >= 1 {
+		return Hann(n, sym)
+	}
+	a := float64(alpha)
+	return symmetricWindow(n, sym, func(m int) []FLOAT {
+		w := make([]FLOAT, m)
+		for k := range w {
+			x := float64(k) / float64(m-1)
+			if x > 0.5 {
+				x = 1 - x
+			}
+			if x < a/2 {
+				w[k] = FLOAT(0.5 * (1 + math.Cos(math.Pi*(2*x/a-1))))
+			} else {
+				w[k] = 1
+			}
+		}
+		return w
+	})
+}
+
+// Gaussian returns a Gaussian window of length n with the given standard
+// deviation sigma, measured in samples. If n <= 0 the returned slice is empty.
+// If sigma <= 0, nil is returned.
+func Gaussian(n int, sigma FLOAT, sym WindowSymmetry) []FLOAT {
+	if !(sigma > 0) {
+		return nil
+	}
+	s := float64(sigma)
+	return symmetricWindow(n, sym, func(m int) []FLOAT {
+		w := make([]FLOAT, m)
+		center := float64(m-1) / 2
+		for k := range w {
+			x := (float64(k) - center) / s
+			w[k] = FLOAT(math.Exp(-0.5 * x * x))
+		}
+		return w
+	})
+}
+
+// DolphChebyshev returns a Dolph-Chebyshev window of length n. All of its side
+// lobes lie attenuation dB below the main lobe, which gives the narrowest main
+// lobe possible for that side lobe level. If n <= 0 the returned slice is
+// empty.
+func DolphChebyshev(n int, attenuation FLOAT, sym WindowSymmetry) []FLOAT {
+	at := math.Abs(float64(attenuation))
+	return symmetricWindow(n, sym, func(m int) []FLOAT {
+		// The window's zero phase spectrum is the Chebyshev polynomial of order
+		// m-1 evaluated at beta*cos(w/2). Sampling it at m frequencies and
+		// taking the inverse DFT gives back the window exactly.
+		order := float64(m - 1)
+		beta := math.Cosh(math.Acosh(math.Pow(10, at/20)) / order)
+		p := make([]float64, m)
+		for k := range p {
+			x := beta * math.Cos(math.Pi*float64(k)/float64(m))
+			switch {
+			case x > 1:
+				p[k] = math.Cosh(order * math.Acosh(x))
+			case x < -1:
+				p[k] = math.Cosh(order * math.Acosh(-x))
+				if m%2 == 0 {
+					p[k] = -p[k]
+				}
+			default:
+				p[k] = math.Cos(order * math.Acos(x))
+			}
+		}
+
+		w := make([]FLOAT, m)
+		var max float64
+		for i := range w {
+			t := float64(i) - float64(m-1)/2
+			var sum float64
+			for k := range p {
+				sum += p[k] * math.Cos(2*math.Pi*float64(k)*t/float64(m))
+			}
+			if sum > max {
+				max = sum
+			}
+			w[i] = FLOAT(sum)
+		}
+		for i := range w {
+			w[i] = FLOAT(float64(w[i]) / max)
+		}
+		return w
+	})
+}
+
+// symmetricWindow creates a window of length n using the function symmetric,
+// which must return a symmetric window of the length given to it, with m >= 2.
+func symmetricWindow(n int, sym WindowSymmetry, symmetric func(m int) []FLOAT) []FLOAT {
+	if n <= 0 {
+		return nil
+	}
+	if n == 1 {
+		return []FLOAT{1}
+	}
+	if sym == Periodic {
+		return symmetric(n + 1)[:n]
+	}
+	return symmetric(n)
+}
+
+// besselI0 returns the modified Bessel function of the first kind of order 0.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	y := x * x / 4
+	for k := 1; k < 500; k++ {
+		term *= y / float64(k*k)
+		sum += term
+		if term < sum*1e-17 {
+			break
+		}
+	}
+	return sum
+}
+
+// CoherentGain returns the coherent gain of window w, i.e. its average value.
+// A sinusoid with amplitude A that lies exactly on a frequency bin shows up in
+// the spectrum of the windowed signal with amplitude A*CoherentGain(w), so
+// amplitudes must be divided by it for correction.
+// For an empty window 0 is returned.
+func CoherentGain(w []FLOAT) FLOAT {
+	return Average(w)
+}
+
+// ENBW returns the equivalent noise bandwidth of window w in bins, i.e.
+// len(w) * sum(w*w) / sum(w)^2. To get the bandwidth in Hz, multiply it by the
+// bin width sampleRate/len(w). A rectangular window has an ENBW of 1, a Hann
+// window of 1.5. Noise power densities must be divided by it for correction.
+// For an empty or all zero window 0 is returned.
+func ENBW(w []FLOAT) FLOAT {
+	var sum, sumSquares float64
+	for _, v := range w {
+		sum += float64(v)
+		sumSquares += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return 0
+	}
+	return FLOAT(float64(len(w)) * sumSquares / (sum * sum))
+}
+
+// ScallopingLoss returns the scalloping loss of window w in dB, as a positive
+// number. This is the worst case amplitude error for a sinusoid that lies
+// exactly between two frequency bins. A rectangular window has a scalloping
+// loss of 3.92 dB, a Hann window of 1.42 dB.
+// For an empty or all zero window 0 is returned.
+func ScallopingLoss(w []FLOAT) FLOAT {
+	var sum float64
+	var halfBin complex128
+	for k, v := range w {
+		sum += float64(v)
+		theta := -math.Pi * float64(k) / float64(len(w))
+		halfBin += complex(float64(v)*math.Cos(theta), float64(v)*math.Sin(theta))
+	}
+	if sum == 0 {
+		return 0
+	}
+	gain := math.Hypot(real(halfBin), imag(halfBin)) / math.Abs(sum)
+	return FLOAT(-20 * math.Log10(gain))
+}